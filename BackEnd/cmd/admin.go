@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"net/mail"
+	"os"
+	"strconv"
+
+	"github.com/redha28/blogku/internals/config"
+	"github.com/redha28/blogku/internals/models"
+	"github.com/redha28/blogku/internals/repositories"
+	"github.com/redha28/blogku/pkg"
+)
+
+const adminUsage = `Usage: blogku admin <command>
+
+Commands:
+  create -username NAME -email EMAIL [-role owner|admin]
+                         create an admin; the password is read from stdin
+  reset-password USER    set a new password read from stdin and clear a forced reset
+  disable USER           disable USER; the last active owner cannot be disabled
+
+USER is a username or email address, for example:
+
+  echo "$NEW_PASSWORD" | blogku admin reset-password admin
+`
+
+// adminCommand manages admin accounts without going through the API
+type adminCommand struct {
+	repository      repositories.AuthRepository
+	auditRepository repositories.AuditRepository
+}
+
+// runAdmin implements the admin subcommand and returns the process exit code
+func runAdmin(cfg *config.Config, args []string) int {
+	name, rest, ok := subcommand(args, adminUsage)
+	if !ok {
+		return 2
+	}
+
+	var command func(a *adminCommand, ctx context.Context, args []string) int
+	switch name {
+	case "create":
+		command = (*adminCommand).create
+	case "reset-password":
+		command = (*adminCommand).resetPassword
+	case "disable":
+		command = (*adminCommand).disable
+	default:
+		fmt.Fprint(os.Stderr, adminUsage)
+		return 2
+	}
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	// New hashes use the configured policy, like the API
+	pkg.SetHashPolicy(cfg.Password.HashConfig())
+
+	mySql, ok := connectMySQL(cfg.Database.MySQL())
+	if !ok {
+		return 1
+	}
+	defer mySql.Close()
+
+	return command(&adminCommand{
+		repository:      repositories.NewAuthRepository(mySql),
+		auditRepository: repositories.NewAuditRepository(mySql),
+	}, ctx, rest)
+}
+
+// create creates an admin
+func (a *adminCommand) create(ctx context.Context, args []string) int {
+	flags := flag.NewFlagSet("admin create", flag.ContinueOnError)
+	username := flags.String("username", "", "username of the new admin")
+	email := flags.String("email", "", "email address of the new admin")
+	role := flags.String("role", models.RoleAdmin, "role of the new admin, owner or admin")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *username == "" || *email == "" || flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+	if !models.ValidUsername(*username) {
+		fmt.Fprintln(os.Stderr, "username must be 3 to 64 letters, digits, underscores, dots or hyphens")
+		return 2
+	}
+	if address, err := mail.ParseAddress(*email); err != nil || address.Address != *email {
+		fmt.Fprintf(os.Stderr, "invalid email address %q\n", *email)
+		return 2
+	}
+	if *role != models.RoleOwner && *role != models.RoleAdmin {
+		fmt.Fprintf(os.Stderr, "role must be %s or %s\n", models.RoleOwner, models.RoleAdmin)
+		return 2
+	}
+
+	exists, err := a.repository.CheckIfAdminExists(ctx, *username, *email)
+	if err != nil {
+		pkg.Error("Failed to check existing admins", err)
+		return 1
+	}
+	if exists {
+		fmt.Fprintln(os.Stderr, "username or email already exists")
+		return 1
+	}
+
+	hashedPass, ok := hashPassword("Password for " + *username + ": ")
+	if !ok {
+		return 1
+	}
+
+	admin := models.AdminCreate{Username: *username, Email: *email, Role: *role}
+	id, err := a.repository.CreateAdmin(ctx, admin, hashedPass)
+	if err != nil {
+		pkg.Error("Failed to create admin", err)
+		return 1
+	}
+
+	recordCLIAudit(ctx, a.auditRepository, models.AuditEvent{
+		Action:     models.AuditAdminCreate,
+		TargetType: models.AuditTargetAdmin,
+		TargetID:   strconv.FormatInt(id, 10),
+	}, map[string]any{
+		"username": admin.Username,
+		"email":    admin.Email,
+		"role":     admin.Role,
+	})
+
+	fmt.Printf("created %s %s (id %d)\n", admin.Role, admin.Username, id)
+	return 0
+}
+
+// resetPassword sets a new password and clears a forced reset
+func (a *adminCommand) resetPassword(ctx context.Context, args []string) int {
+	admin, code := a.loadAdmin(ctx, args)
+	if admin == nil {
+		return code
+	}
+
+	hashedPass, ok := hashPassword("New password for " + admin.Username + ": ")
+	if !ok {
+		return 1
+	}
+
+	if err := a.repository.UpdatePassword(ctx, admin.ID, hashedPass); err != nil {
+		pkg.Error("Failed to reset password", err)
+		return 1
+	}
+
+	recordCLIAudit(ctx, a.auditRepository, models.AuditEvent{
+		Action:     models.AuditAdminPasswordSet,
+		TargetType: models.AuditTargetAdmin,
+		TargetID:   strconv.Itoa(admin.ID),
+	}, nil)
+
+	fmt.Printf("password of %s reset\n", admin.Username)
+	return 0
+}
+
+// disable disables an admin so it can no longer log in or use its sessions and API keys
+func (a *adminCommand) disable(ctx context.Context, args []string) int {
+	admin, code := a.loadAdmin(ctx, args)
+	if admin == nil {
+		return code
+	}
+
+	if admin.Disabled {
+		fmt.Printf("%s is already disabled\n", admin.Username)
+		return 0
+	}
+	if err := a.repository.SetAdminDisabled(ctx, admin.ID, true); err != nil {
+		if errors.Is(err, repositories.ErrLastOwner) {
+			fmt.Fprintln(os.Stderr, "at least one active owner is required")
+			return 1
+		}
+		pkg.Error("Failed to disable admin", err)
+		return 1
+	}
+
+	recordCLIAudit(ctx, a.auditRepository, models.AuditEvent{
+		Action:     models.AuditAdminDisable,
+		TargetType: models.AuditTargetAdmin,
+		TargetID:   strconv.Itoa(admin.ID),
+	}, nil)
+
+	fmt.Printf("%s disabled\n", admin.Username)
+	return 0
+}
+
+// loadAdmin finds the admin named by the single username or email argument
+func (a *adminCommand) loadAdmin(ctx context.Context, args []string) (*models.Admin, int) {
+	if len(args) != 1 {
+		fmt.Fprint(os.Stderr, adminUsage)
+		return nil, 2
+	}
+
+	admin, _, err := a.repository.GetAdminForAuth(ctx, args[0])
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			fmt.Fprintf(os.Stderr, "no admin with username or email %q\n", args[0])
+			return nil, 1
+		}
+		pkg.Error("Failed to load admin", err)
+		return nil, 1
+	}
+	return admin, 0
+}
+
+// hashPassword reads a password from stdin and hashes it with the configured policy
+func hashPassword(prompt string) (string, bool) {
+	password, err := readPassword(prompt)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return "", false
+	}
+
+	hasher := pkg.InitHashConfig()
+	hasher.UsePolicyConfig()
+	hashedPass, err := hasher.GenHashedPassword(password)
+	if err != nil {
+		pkg.Error("Failed to hash password", err)
+		return "", false
+	}
+	return hashedPass, true
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/redha28/blogku/internals/config"
+	"github.com/redha28/blogku/internals/models"
+	"github.com/redha28/blogku/internals/repositories"
+	"github.com/redha28/blogku/pkg"
+	"golang.org/x/term"
+)
+
+const usage = `Usage: blogku [command]
+
+Without a command blogku serves the API.
+
+Commands:
+  migrate up|down|status|force   manage the database schema
+  admin create                   create an admin, reading the password from stdin
+  admin reset-password USER      set a new password for USER, reading it from stdin
+  admin disable USER             disable USER so it can no longer log in
+  cache flush                    remove every cached blog list and post
+  uploads gc                     delete uploaded files that no row references (-dry-run lists them)
+  search reindex                 rebuild the search index
+
+USER is a username or email address. Run a command with -h for its options.
+`
+
+// cliUserAgent is stored as the user agent of audit events recorded by commands
+const cliUserAgent = "blogku-cli"
+
+// commands are the subcommands of the binary. Each returns the process exit code.
+var commands = map[string]func(cfg *config.Config, args []string) int{
+	"migrate": runMigrate,
+	"admin":   runAdmin,
+	"cache":   runCache,
+	"uploads": runUploads,
+	"search":  runSearch,
+}
+
+// dispatch runs the subcommand named by args, or the server when there is none
+func dispatch(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		return run(cfg)
+	}
+
+	command, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprint(os.Stderr, usage)
+		if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+			return 0
+		}
+		return 2
+	}
+	return command(cfg, args[1:])
+}
+
+// commandContext is cancelled on SIGINT or SIGTERM
+func commandContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// connectMySQL opens the database for a command, logging the failure
+func connectMySQL(cfg pkg.MySQLConfig) (*sql.DB, bool) {
+	mySql, err := pkg.Connect(cfg)
+	if err != nil {
+		pkg.Error("Unable to create database connection pool", err)
+		return nil, false
+	}
+	return mySql, true
+}
+
+// subcommand splits args into the subcommand name and its arguments
+func subcommand(args []string, help string) (string, []string, bool) {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(os.Stderr, help)
+		return "", nil, false
+	}
+	return args[0], args[1:], true
+}
+
+// readPassword reads a password from the first line of stdin. When stdin is a terminal it
+// prompts for the password and reads it without echoing it.
+func readPassword(prompt string) (string, error) {
+	var password string
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, prompt)
+		input, err := term.ReadPassword(fd)
+		// The newline typed by the user was not echoed either
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		password = string(input)
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+
+	if len(password) < 8 {
+		return "", errors.New("password must be at least 8 characters")
+	}
+	return password, nil
+}
+
+// recordCLIAudit records an action taken from the command line. Commands have no actor or IP.
+func recordCLIAudit(ctx context.Context, repository repositories.AuditRepository, event models.AuditEvent, after any) {
+	event.UserAgent = cliUserAgent
+	if after != nil {
+		data, err := json.Marshal(after)
+		if err != nil {
+			pkg.Error("Failed to encode audit event", err)
+		}
+		event.After = data
+	}
+
+	if err := repository.Record(ctx, event); err != nil {
+		pkg.ErrorWithFields("Failed to record audit event", err, map[string]any{
+			"action":     event.Action,
+			"targetType": event.TargetType,
+			"targetId":   event.TargetID,
+		})
+	}
+}
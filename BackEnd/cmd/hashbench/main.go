@@ -0,0 +1,97 @@
+// Command hashbench measures argon2id on the current host and suggests
+// ARGON2_* settings that keep a single password hash within a time budget.
+//
+// Usage:
+//
+//	go run ./cmd/hashbench -target 500ms -memory 65536 -threads 2
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"runtime"
+	"time"
+
+	"github.com/redha28/blogku/internals/config"
+	"github.com/redha28/blogku/pkg"
+)
+
+func main() {
+	target := flag.Duration("target", 500*time.Millisecond, "maximum time one hash may take")
+	memory := flag.Uint("memory", 64*1024, "memory to use in KiB")
+	threads := flag.Uint("threads", defaultThreads(), "parallelism")
+	maxTime := flag.Uint("max-time", 20, "highest iteration count to try")
+	rounds := flag.Int("rounds", 3, "hashes per measurement, the median is used")
+	flag.Parse()
+
+	if *threads == 0 || *threads > 255 {
+		log.Fatalf("threads must be between 1 and 255")
+	}
+
+	cfg, err := config.Read()
+	if err != nil {
+		log.Fatal(err)
+	}
+	hasher := pkg.InitHashConfig()
+	*hasher = cfg.Password.HashConfig()
+	fmt.Printf("Current policy: ARGON2_TIME=%d ARGON2_MEMORY=%d ARGON2_THREADS=%d -> %s\n\n",
+		hasher.Time, hasher.Memory, hasher.Threads, measure(hasher, *rounds))
+
+	fmt.Printf("Target %s, memory %d KiB, threads %d, CPUs %d\n", *target, *memory, *threads, runtime.NumCPU())
+	fmt.Println("time  duration")
+
+	var best uint32
+	for t := uint32(1); t <= uint32(*maxTime); t++ {
+		hasher.UseConfig(t, uint32(*memory), hasher.KeyLen, hasher.SaltLen, uint8(*threads))
+		duration := measure(hasher, *rounds)
+		fmt.Printf("%4d  %s\n", t, duration)
+		if duration > *target {
+			break
+		}
+		best = t
+	}
+
+	if best == 0 {
+		fmt.Println("\nEven one iteration exceeds the target, lower -memory or raise -target.")
+		return
+	}
+
+	fmt.Println("\nSuggested settings:")
+	fmt.Printf("ARGON2_TIME=%d\n", best)
+	fmt.Printf("ARGON2_MEMORY=%d\n", *memory)
+	fmt.Printf("ARGON2_THREADS=%d\n", *threads)
+	fmt.Println("\nExisting hashes weaker than this policy are upgraded on the next successful login.")
+}
+
+// defaultThreads uses up to four CPUs, leaving room for concurrent requests
+func defaultThreads() uint {
+	if cpus := runtime.NumCPU(); cpus < 4 {
+		return uint(cpus)
+	}
+	return 4
+}
+
+// measure returns the median duration of hashing a password with the config
+func measure(hasher *pkg.HashConfig, rounds int) time.Duration {
+	if rounds < 1 {
+		rounds = 1
+	}
+
+	durations := make([]time.Duration, 0, rounds)
+	for i := 0; i < rounds; i++ {
+		start := time.Now()
+		if _, err := hasher.GenHashedPassword("benchmark-password"); err != nil {
+			log.Fatalf("Failed to hash: %v", err)
+		}
+		durations = append(durations, time.Since(start))
+	}
+
+	// Insertion sort is plenty for a handful of samples
+	for i := 1; i < len(durations); i++ {
+		for j := i; j > 0 && durations[j] < durations[j-1]; j-- {
+			durations[j], durations[j-1] = durations[j-1], durations[j]
+		}
+	}
+	return durations[len(durations)/2].Round(time.Millisecond)
+}
@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"syscall"
+	"time"
 
-	_ "github.com/joho/godotenv/autoload"
 	// "github.com/redha28/blogku/internal/handlers"
 
-	"github.com/redha28/blogku/internals/middlewares"
+	"github.com/redha28/blogku/internals/config"
+	"github.com/redha28/blogku/internals/models"
 	"github.com/redha28/blogku/internals/routes"
 	"github.com/redha28/blogku/pkg"
 
@@ -35,49 +41,207 @@ import (
 // @host localhost:8080
 // @BasePath /
 func main() {
+	// Load configuration before anything else so a bad setting fails with a full report
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
 	// Initialize logger
-	logPath := filepath.Join("logs", "app.log")
-	logger, err := pkg.InitLogger(pkg.LevelDebug, logPath)
+	logger, err := pkg.InitLogger(cfg.Log.LoggerConfig())
 	if err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
-	defer logger.Close()
+
+	stopReopen := reopenLogOnSIGHUP(logger)
+
+	code := dispatch(cfg, os.Args[1:])
+
+	stopReopen()
+
+	// The logger is closed last so every shutdown step is recorded
+	pkg.Info("Shutdown complete")
+	if err := logger.Close(); err != nil {
+		log.Printf("Failed to close log file: %v", err)
+	}
+	os.Exit(code)
+}
+
+// Background work queued by requests runs on backgroundWorkers goroutines, and further tasks are
+// dropped while backgroundQueueSize tasks are waiting
+const (
+	backgroundWorkers   = 4
+	backgroundQueueSize = 100
+)
+
+// run serves requests until SIGINT or SIGTERM, then drains in-flight requests and background
+// tasks and closes Redis and MySQL. It returns the process exit code; os.Exit is left to main so deferred cleanup always runs.
+func run(cfg *config.Config) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	pkg.Info("Starting Blog CMS application...")
 
+	pkg.SetHashPolicy(cfg.Password.HashConfig())
+	pkg.SetJWTIssuer(cfg.JWT.Issuer)
+
+	// Load JWT keys up front so a broken key file fails at startup
+	keySet, err := pkg.LoadKeySet(cfg.JWT.KeysFile, cfg.JWT.Secret)
+	if err != nil {
+		pkg.Error("Unable to load JWT keys", err)
+		return 1
+	}
+	pkg.SetKeySet(keySet)
+
+	// Tracing is set up before the database and Redis clients so their instrumentation uses the provider
+	tracing := cfg.Tracing.TracingConfig()
+	shutdownTracing, err := pkg.InitTracing(ctx, tracing)
+	if err != nil {
+		pkg.Error("Unable to initialize tracing", err)
+		return 1
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			pkg.Error("Failed to flush traces", err)
+		}
+	}()
+	if tracing.Exporter != "none" {
+		pkg.Info("Exporting traces with the " + tracing.Exporter + " exporter")
+	}
+
+	// Apply pending migrations before serving; the migration lock lets only one replica run them
+	if cfg.Database.AutoMigrate {
+		if err := autoMigrate(ctx, cfg.Database); err != nil {
+			pkg.Error("Failed to migrate database", err)
+			return 1
+		}
+	}
+
 	// Connect to MySQL
-	mySql, err := pkg.Connect()
+	mySql, err := pkg.Connect(cfg.Database.MySQL())
 	if err != nil {
 		pkg.Error("Unable to create database connection pool", err)
-		os.Exit(1)
+		return 1
 	}
 	defer func() {
 		pkg.Info("Closing DB connection")
-		mySql.Close()
+		if err := mySql.Close(); err != nil {
+			pkg.Error("Failed to close DB connection", err)
+		}
 	}()
 
 	// Connect to Redis
 	pkg.Info("Connecting to Redis...")
-	rdb := pkg.RedisConnect()
+	rdb := pkg.RedisConnect(cfg.Redis.Addr(), cfg.Redis.Password, cfg.Redis.DB)
+	defer func() {
+		pkg.Info("Closing Redis connection")
+		if err := rdb.Close(); err != nil {
+			pkg.Error("Failed to close Redis connection", err)
+		}
+	}()
+
+	// Reset emails are sent after the response on a bounded worker, drained at shutdown
+	worker := pkg.NewWorker(backgroundWorkers, backgroundQueueSize)
+
+	// Custom binding tags must be registered before the first request is bound
+	if err := models.RegisterValidations(); err != nil {
+		pkg.Error("Unable to register request validations", err)
+		return 1
+	}
 
 	// Initialize router
 	pkg.Info("Initializing router...")
-	router := routes.InitRouter(mySql, rdb)
+	router, err := routes.InitRouter(cfg, mySql, rdb, worker)
+	if err != nil {
+		pkg.Error("Unable to initialize router", err)
+		return 1
+	}
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// Middleware
-	router.Use(middlewares.LoggerMiddleware())
-	router.Use(middlewares.RecoveryMiddleware())
-
 	// Add CORS middleware
 	// router.Use(handlers.)
 
+	server := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           router,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+
 	// Start server
-	serverAddr := "localhost:8080"
-	pkg.Info("Server starting on " + serverAddr)
-	if err := router.Run(serverAddr); err != nil {
+	serverErr := make(chan error, 1)
+	go func() {
+		pkg.Info("Server starting on " + cfg.Server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
 		pkg.Error("Failed to start server", err)
-		os.Exit(1)
+		return 1
+	case <-ctx.Done():
+	}
+
+	// Restore default signal handling so a second signal kills the process without waiting
+	stop()
+
+	// Fail readiness first so load balancers stop sending new requests before the listener closes
+	pkg.BeginShutdown()
+	pkg.Info(fmt.Sprintf("Shutdown signal received, failing readiness for %s", cfg.Server.DrainDelay))
+	time.Sleep(cfg.Server.DrainDelay)
+
+	pkg.Info(fmt.Sprintf("Draining requests for up to %s", cfg.Server.ShutdownTimeout))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	code := 0
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		pkg.Error("Requests still running after the shutdown timeout, closing connections", err)
+		server.Close()
+		code = 1
+	}
+	if err := <-serverErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		pkg.Error("Server stopped with an error", err)
+		code = 1
+	}
+	pkg.Info("Server stopped")
+
+	// No request can queue more work now. The tasks store reset tokens, so they finish before MySQL is closed.
+	pkg.Info("Waiting for background tasks")
+	if err := worker.Stop(shutdownCtx); err != nil {
+		pkg.Error("Background tasks still running after the shutdown timeout, cancelling them", err)
+		code = 1
+	}
+
+	return code
+}
+
+// reopenLogOnSIGHUP reopens the log file on SIGHUP so an external logrotate can move it away.
+// The returned function stops listening for the signal.
+func reopenLogOnSIGHUP(logger *pkg.Logger) func() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range hup {
+			if err := logger.Reopen(); err != nil {
+				log.Printf("Failed to reopen log file: %v", err)
+				continue
+			}
+			pkg.Info("Reopened log file after SIGHUP")
+		}
+	}()
+
+	return func() {
+		signal.Stop(hup)
+		close(hup)
+		<-done
 	}
 }
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/redha28/blogku/internals/config"
+	"github.com/redha28/blogku/internals/migrate"
+	"github.com/redha28/blogku/migrations"
+	"github.com/redha28/blogku/pkg"
+)
+
+const migrateUsage = `Usage: blogku migrate <command>
+
+Commands:
+  up [N]         apply all pending migrations, or the next N
+  down [N]       revert the last migration, or the last N
+  status         list migrations and the applied schema version
+  force VERSION  record VERSION as applied without running SQL, to recover from a dirty schema (0 clears it)
+`
+
+// runMigrate implements the migrate subcommand and returns the process exit code
+func runMigrate(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, migrateUsage) }
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	command, rest := flags.Arg(0), flags.Args()[1:]
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	mySql, ok := connectMySQL(cfg.Database.Migrator())
+	if !ok {
+		return 1
+	}
+	defer mySql.Close()
+
+	migrator, err := migrate.New(mySql, migrations.FS)
+	if err != nil {
+		pkg.Error("Unable to load migrations", err)
+		return 1
+	}
+	migrator.LockTimeout = cfg.Database.MigrateLockTimeout
+
+	switch command {
+	case "up":
+		steps, ok := parseSteps(rest, 0)
+		if !ok {
+			flags.Usage()
+			return 2
+		}
+		applied, err := migrator.Up(ctx, steps)
+		for _, migration := range applied {
+			fmt.Printf("applied %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return migrateFailed(err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		steps, ok := parseSteps(rest, 1)
+		if !ok || steps == 0 {
+			flags.Usage()
+			return 2
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return migrateFailed(err)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("no applied migrations")
+		}
+	case "status":
+		if len(rest) != 0 {
+			flags.Usage()
+			return 2
+		}
+		return printMigrationStatus(ctx, migrator)
+	case "force":
+		if len(rest) != 1 {
+			flags.Usage()
+			return 2
+		}
+		version, err := strconv.ParseInt(rest[0], 10, 64)
+		if err != nil || version < 0 {
+			flags.Usage()
+			return 2
+		}
+		if err := migrator.Force(ctx, version); err != nil {
+			return migrateFailed(err)
+		}
+		fmt.Printf("schema version forced to %d\n", version)
+	default:
+		flags.Usage()
+		return 2
+	}
+	return 0
+}
+
+// parseSteps reads the optional step count argument
+func parseSteps(args []string, fallback int) (int, bool) {
+	switch len(args) {
+	case 0:
+		return fallback, true
+	case 1:
+		steps, err := strconv.Atoi(args[0])
+		return steps, err == nil && steps >= 0
+	default:
+		return 0, false
+	}
+}
+
+// printMigrationStatus lists every migration and the recorded schema version
+func printMigrationStatus(ctx context.Context, migrator *migrate.Migrator) int {
+	statuses, version, dirty, err := migrator.Status(ctx)
+	if err != nil {
+		return migrateFailed(err)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tNAME\tSTATUS")
+	pending := 0
+	for _, status := range statuses {
+		state := "applied"
+		if !status.Applied {
+			state = "pending"
+			pending++
+		}
+		if dirty && status.Version == version {
+			state = "dirty"
+		}
+		fmt.Fprintf(writer, "%d\t%s\t%s\n", status.Version, status.Name, state)
+	}
+	writer.Flush()
+
+	fmt.Printf("\nschema version %d, latest %d, %d pending\n", version, migrator.Latest(), pending)
+	if dirty {
+		fmt.Println("the schema is dirty: repair it by hand, then run \"blogku migrate force <version>\"")
+		return 1
+	}
+	return 0
+}
+
+// migrateFailed reports a migration error and returns the exit code
+func migrateFailed(err error) int {
+	var dirty *migrate.DirtyError
+	if errors.As(err, &dirty) || errors.Is(err, migrate.ErrLocked) {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	pkg.Error("Migration failed", err)
+	return 1
+}
+
+// autoMigrate applies pending migrations on startup over a connection of its own, the only one
+// that allows multiple statements. Replicas starting together wait on the migration lock, then
+// find nothing left to apply.
+func autoMigrate(ctx context.Context, cfg config.DatabaseConfig) error {
+	db, err := pkg.Connect(cfg.Migrator())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		return err
+	}
+	migrator.LockTimeout = cfg.MigrateLockTimeout
+
+	applied, err := migrator.Up(ctx, 0)
+	for _, migration := range applied {
+		pkg.InfoWithFields("Applied migration", map[string]any{
+			"version": migration.Version,
+			"name":    migration.Name,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		pkg.Info("Database schema is up to date")
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redha28/blogku/internals/config"
+	"github.com/redha28/blogku/internals/repositories"
+	"github.com/redha28/blogku/internals/utils"
+	"github.com/redha28/blogku/pkg"
+)
+
+const cacheUsage = `Usage: blogku cache flush
+
+Removes every cached blog list and blog post from Redis. Login lockouts and rate limits are kept.
+`
+
+const uploadsUsage = `Usage: blogku uploads gc [-dry-run] [-min-age 24h] [-quarantine DIR]
+
+Deletes or quarantines the files in UPLOAD_DIR that no blog image or admin avatar references.
+`
+
+const searchUsage = `Usage: blogku search reindex
+`
+
+// runCache implements the cache subcommand and returns the process exit code
+func runCache(cfg *config.Config, args []string) int {
+	name, rest, ok := subcommand(args, cacheUsage)
+	if !ok {
+		return 2
+	}
+	if name != "flush" || len(rest) != 0 {
+		fmt.Fprint(os.Stderr, cacheUsage)
+		return 2
+	}
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	rdb := pkg.RedisConnect(cfg.Redis.Addr(), cfg.Redis.Password, cfg.Redis.DB)
+	defer rdb.Close()
+
+	removed, err := repositories.NewBlogRepository(nil, rdb, cfg.Uploads.Dir).ClearCache(ctx)
+	if err != nil {
+		pkg.Error("Failed to flush blog caches", err)
+		return 1
+	}
+
+	fmt.Printf("removed %d cached keys\n", removed)
+	return 0
+}
+
+// runUploads implements the uploads subcommand and returns the process exit code
+func runUploads(cfg *config.Config, args []string) int {
+	name, rest, ok := subcommand(args, uploadsUsage)
+	if !ok {
+		return 2
+	}
+	if name != "gc" {
+		fmt.Fprint(os.Stderr, uploadsUsage)
+		return 2
+	}
+
+	var opts utils.UploadGCOptions
+	flags := flag.NewFlagSet("uploads gc", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, uploadsUsage)
+		flags.PrintDefaults()
+	}
+	flags.BoolVar(&opts.DryRun, "dry-run", false, "only report the orphaned files")
+	flags.DurationVar(&opts.MinAge, "min-age", 24*time.Hour, "keep orphans modified more recently than this")
+	flags.StringVar(&opts.QuarantineDir, "quarantine", "", "move orphans into a timestamped directory below this one instead of deleting them")
+	if err := flags.Parse(rest); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 || opts.MinAge < 0 {
+		flags.Usage()
+		return 2
+	}
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	mySql, ok := connectMySQL(cfg.Database.MySQL())
+	if !ok {
+		return 1
+	}
+	defer mySql.Close()
+
+	repository := repositories.NewUploadRepository(mySql)
+	report, err := utils.NewUtils(cfg.Uploads.Dir).CollectOrphanedUploads(ctx, repository.ReferencedFiles, opts)
+	if err != nil {
+		pkg.Error("Failed to collect orphaned uploads", err)
+		return 1
+	}
+
+	action := "deleted"
+	switch {
+	case opts.DryRun:
+		action = "orphan"
+	case report.QuarantinedTo != "":
+		action = "quarantined"
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, orphan := range report.Orphans {
+		state := action
+		if orphan.Err != nil {
+			state = "failed: " + orphan.Err.Error()
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", orphan.Name, formatBytes(orphan.Size), orphan.ModTime.Format(time.DateTime), state)
+	}
+	writer.Flush()
+
+	var orphanBytes int64
+	for _, orphan := range report.Orphans {
+		orphanBytes += orphan.Size
+	}
+	fmt.Printf("\nscanned %d files: %d orphaned (%s), %d kept as younger than %s\n",
+		report.Scanned, len(report.Orphans), formatBytes(orphanBytes), report.Recent, opts.MinAge)
+	if opts.DryRun {
+		fmt.Println("dry run, nothing was changed")
+		return 0
+	}
+	fmt.Printf("%s %d files, reclaimed %s\n", action, report.Collected, formatBytes(report.ReclaimedBytes))
+	if report.QuarantinedTo != "" {
+		fmt.Printf("quarantined files are in %s\n", report.QuarantinedTo)
+	}
+	if report.Failed() > 0 {
+		return 1
+	}
+	return 0
+}
+
+// formatBytes renders a size such as 1.5 MiB
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// runSearch implements the search subcommand. blogku has no search index yet, so there is nothing
+// to rebuild; the command fails instead of pretending to succeed.
+func runSearch(_ *config.Config, args []string) int {
+	name, rest, ok := subcommand(args, searchUsage)
+	if !ok {
+		return 2
+	}
+	if name != "reindex" || len(rest) != 0 {
+		fmt.Fprint(os.Stderr, searchUsage)
+		return 2
+	}
+
+	fmt.Fprintln(os.Stderr, "blogku has no search index to rebuild: posts are read straight from MySQL")
+	return 1
+}
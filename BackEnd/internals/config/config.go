@@ -0,0 +1,465 @@
+// Package config loads the application configuration from defaults, an optional YAML file,
+// a .env file and the environment, in increasing order of precedence, and validates it once at startup.
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redha28/blogku/pkg"
+)
+
+// Config is the complete application configuration
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	App       AppConfig       `yaml:"app"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Redis     RedisConfig     `yaml:"redis"`
+	JWT       JWTConfig       `yaml:"jwt"`
+	Password  PasswordConfig  `yaml:"password"`
+	Login     LoginConfig     `yaml:"login"`
+	Audit     AuditConfig     `yaml:"audit"`
+	Mail      MailConfig      `yaml:"mail"`
+	Cookie    CookieConfig    `yaml:"cookie"`
+	CORS      CORSConfig      `yaml:"cors"`
+	Security  SecurityConfig  `yaml:"security"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Uploads   UploadsConfig   `yaml:"uploads"`
+	Log       LogConfig       `yaml:"log"`
+	Metrics   MetricsConfig   `yaml:"metrics"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+}
+
+// ServerConfig configures the HTTP listener.
+// On SIGTERM readiness fails for DrainDelay so load balancers stop routing,
+// then in-flight requests get ShutdownTimeout to finish.
+// X-Forwarded-For and X-Forwarded-Proto are only believed from TrustedProxies, which is empty by default.
+type ServerConfig struct {
+	Addr              string        `yaml:"addr" env:"SERVER_ADDR"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	DrainDelay        time.Duration `yaml:"drain_delay" env:"SERVER_DRAIN_DELAY"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+	TrustedProxies    []string      `yaml:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES"`
+}
+
+// AppConfig holds settings about the public site
+type AppConfig struct {
+	URL        string `yaml:"url" env:"APP_URL"`
+	TOTPIssuer string `yaml:"totp_issuer" env:"TOTP_ISSUER"`
+}
+
+// DatabaseConfig configures the MySQL connection pool.
+// With AutoMigrate pending migrations are applied at startup, and replicas wait up to MigrateLockTimeout for each other.
+type DatabaseConfig struct {
+	User               string        `yaml:"user" env:"DBUSER"`
+	Password           string        `yaml:"password" env:"DBPASS"`
+	Host               string        `yaml:"host" env:"DBHOST"`
+	Port               int           `yaml:"port" env:"DBPORT"`
+	Name               string        `yaml:"name" env:"DBNAME"`
+	MaxOpenConns       int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns       int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime    time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	AutoMigrate        bool          `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+	MigrateLockTimeout time.Duration `yaml:"migrate_lock_timeout" env:"DB_MIGRATE_LOCK_TIMEOUT"`
+}
+
+// RedisConfig configures the Redis client
+type RedisConfig struct {
+	Host     string `yaml:"host" env:"RDSHOST"`
+	Port     int    `yaml:"port" env:"RDSPORT"`
+	Password string `yaml:"password" env:"RDSPASS"`
+	DB       int    `yaml:"db" env:"RDSDB"`
+}
+
+// JWTConfig configures token signing. Either Secret or KeysFile is required.
+type JWTConfig struct {
+	Secret   string `yaml:"secret" env:"JWT_SECRET"`
+	KeysFile string `yaml:"keys_file" env:"JWT_KEYS_FILE"`
+	Issuer   string `yaml:"issuer" env:"JWT_ISSUER"`
+}
+
+// PasswordConfig is the argon2id hashing policy
+type PasswordConfig struct {
+	Time    int `yaml:"time" env:"ARGON2_TIME"`
+	Memory  int `yaml:"memory" env:"ARGON2_MEMORY"`
+	Threads int `yaml:"threads" env:"ARGON2_THREADS"`
+	KeyLen  int `yaml:"key_len" env:"ARGON2_KEYLEN"`
+	SaltLen int `yaml:"salt_len" env:"ARGON2_SALTLEN"`
+}
+
+// LoginConfig is the failed login throttling policy
+type LoginConfig struct {
+	FailureWindow   time.Duration `yaml:"failure_window" env:"LOGIN_FAILURE_WINDOW"`
+	IdentifierLimit int           `yaml:"identifier_limit" env:"LOGIN_IDENTIFIER_LIMIT"`
+	IPLimit         int           `yaml:"ip_limit" env:"LOGIN_IP_LIMIT"`
+	BaseLockout     time.Duration `yaml:"base_lockout" env:"LOGIN_BASE_LOCKOUT"`
+	MaxLockout      time.Duration `yaml:"max_lockout" env:"LOGIN_MAX_LOCKOUT"`
+}
+
+// AuditConfig configures the audit log.
+// HashKey keys the hashes stored for login identifiers that match no admin, so it must stay the same across restarts.
+type AuditConfig struct {
+	HashKey string `yaml:"hash_key" env:"AUDIT_HASH_KEY"`
+}
+
+// MailConfig selects and configures the mailer
+type MailConfig struct {
+	Driver       string `yaml:"driver" env:"MAIL_DRIVER"`
+	From         string `yaml:"from" env:"MAIL_FROM"`
+	Dir          string `yaml:"dir" env:"MAIL_DIR"`
+	SMTPHost     string `yaml:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort     int    `yaml:"smtp_port" env:"SMTP_PORT"`
+	SMTPUsername string `yaml:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword string `yaml:"smtp_password" env:"SMTP_PASSWORD"`
+}
+
+// CookieConfig holds the attributes of the auth and CSRF cookies
+type CookieConfig struct {
+	Secure   bool   `yaml:"secure" env:"COOKIE_SECURE"`
+	SameSite string `yaml:"same_site" env:"COOKIE_SAMESITE"`
+	Domain   string `yaml:"domain" env:"COOKIE_DOMAIN"`
+}
+
+// CORSConfig describes which browser origins may call the API.
+// Without AllowedOrigins only App.URL is allowed.
+type CORSConfig struct {
+	AllowedOrigins   []string `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	AllowedMethods   []string `yaml:"allowed_methods" env:"CORS_ALLOWED_METHODS"`
+	AllowedHeaders   []string `yaml:"allowed_headers" env:"CORS_ALLOWED_HEADERS"`
+	ExposedHeaders   []string `yaml:"exposed_headers" env:"CORS_EXPOSED_HEADERS,allowempty"`
+	MaxAge           int      `yaml:"max_age" env:"CORS_MAX_AGE"`
+	AllowCredentials bool     `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS"`
+}
+
+// SecurityConfig holds the security headers added to every response
+type SecurityConfig struct {
+	HSTSMaxAge            int    `yaml:"hsts_max_age" env:"HSTS_MAX_AGE"`
+	HSTSIncludeSubdomains bool   `yaml:"hsts_include_subdomains" env:"HSTS_INCLUDE_SUBDOMAINS"`
+	FrameOptions          string `yaml:"frame_options" env:"FRAME_OPTIONS,allowempty"`
+	ReferrerPolicy        string `yaml:"referrer_policy" env:"REFERRER_POLICY,allowempty"`
+	ContentSecurityPolicy string `yaml:"content_security_policy" env:"CONTENT_SECURITY_POLICY,allowempty"`
+}
+
+// RateLimitConfig configures the request rate limiter
+type RateLimitConfig struct {
+	Enabled bool      `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	Store   string    `yaml:"store" env:"RATE_LIMIT_STORE"`
+	Public  RateLimit `yaml:"public" env:"RATE_LIMIT_PUBLIC"`
+	Auth    RateLimit `yaml:"auth" env:"RATE_LIMIT_AUTH"`
+	Admin   RateLimit `yaml:"admin" env:"RATE_LIMIT_ADMIN"`
+	Upload  RateLimit `yaml:"upload" env:"RATE_LIMIT_UPLOAD"`
+}
+
+// RateLimit allows Limit requests per Window, written as "<limit>/<window>" such as "120/1m"
+type RateLimit struct {
+	Limit  int
+	Window time.Duration
+}
+
+// UnmarshalText parses "<limit>/<window>"
+func (r *RateLimit) UnmarshalText(text []byte) error {
+	parts := strings.SplitN(string(text), "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("expected <limit>/<window>, got %q", text)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return fmt.Errorf("invalid limit in %q", text)
+	}
+	window, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return fmt.Errorf("invalid window in %q", text)
+	}
+	r.Limit = limit
+	r.Window = window
+	return nil
+}
+
+// String formats the limit as "<limit>/<window>"
+func (r RateLimit) String() string {
+	return strconv.Itoa(r.Limit) + "/" + r.Window.String()
+}
+
+// UploadsConfig configures where uploaded images are stored
+type UploadsConfig struct {
+	Dir string `yaml:"dir" env:"UPLOAD_DIR"`
+}
+
+// MetricsConfig configures the Prometheus endpoint.
+// When Token is set scrapers must send it as a bearer token.
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled" env:"METRICS_ENABLED"`
+	Path    string `yaml:"path" env:"METRICS_PATH"`
+	Token   string `yaml:"token" env:"METRICS_TOKEN"`
+}
+
+// TracingConfig configures OpenTelemetry tracing.
+// Exporter is otlp, stdout or none; Endpoint is the OTLP/HTTP collector URL.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER"`
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// LogConfig configures the application logger.
+// The log file is rotated when it exceeds MaxSizeMB or every RotateInterval; zero disables either trigger.
+// Attributes whose key contains one of RedactKeys are masked, and emails and IPs can be replaced by keyed hashes.
+type LogConfig struct {
+	Level          string        `yaml:"level" env:"LOG_LEVEL"`
+	Format         string        `yaml:"format" env:"LOG_FORMAT"`
+	File           string        `yaml:"file" env:"LOG_FILE"`
+	MaxSizeMB      int           `yaml:"max_size_mb" env:"LOG_MAX_SIZE_MB"`
+	RotateInterval time.Duration `yaml:"rotate_interval" env:"LOG_ROTATE_INTERVAL"`
+	MaxBackups     int           `yaml:"max_backups" env:"LOG_MAX_BACKUPS"`
+	Compress       bool          `yaml:"compress" env:"LOG_COMPRESS"`
+	RedactKeys     []string      `yaml:"redact_keys" env:"LOG_REDACT_KEYS"`
+	HashEmails     bool          `yaml:"hash_emails" env:"LOG_HASH_EMAILS"`
+	HashIPs        bool          `yaml:"hash_ips" env:"LOG_HASH_IPS"`
+	HashKey        string        `yaml:"hash_key" env:"LOG_HASH_KEY"`
+}
+
+// Default returns the configuration used when nothing is overridden
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Addr:              "localhost:8080",
+			ReadHeaderTimeout: 10 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			DrainDelay:        5 * time.Second,
+			ShutdownTimeout:   25 * time.Second,
+		},
+		App: AppConfig{
+			URL:        "http://localhost:3000",
+			TOTPIssuer: "Blogku",
+		},
+		Database: DatabaseConfig{
+			Host:               "localhost",
+			Port:               3306,
+			MaxOpenConns:       25,
+			MaxIdleConns:       25,
+			ConnMaxLifetime:    5 * time.Minute,
+			MigrateLockTimeout: time.Minute,
+		},
+		Redis: RedisConfig{Host: "localhost", Port: 6379},
+		Password: PasswordConfig{
+			Time:    3,
+			Memory:  64 * 1024,
+			Threads: 2,
+			KeyLen:  32,
+			SaltLen: 16,
+		},
+		Login: LoginConfig{
+			FailureWindow:   15 * time.Minute,
+			IdentifierLimit: 5,
+			IPLimit:         20,
+			BaseLockout:     time.Minute,
+			MaxLockout:      time.Hour,
+		},
+		Mail: MailConfig{
+			Driver:   "file",
+			From:     "Blogku <no-reply@blogku.local>",
+			Dir:      filepath.Join("logs", "mails"),
+			SMTPPort: 1025, // MailHog default
+		},
+		Cookie: CookieConfig{SameSite: "lax"},
+		CORS: CORSConfig{
+			AllowedMethods:   []string{"GET", "POST", "PATCH", "DELETE", "OPTIONS"},
+			AllowedHeaders:   []string{"Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "Accept", "Origin", "Cache-Control", "X-Requested-With", "X-Request-ID"},
+			ExposedHeaders:   []string{"Content-Disposition", "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "X-Request-ID"},
+			MaxAge:           600,
+			AllowCredentials: true,
+		},
+		Security: SecurityConfig{
+			HSTSMaxAge:            31536000,
+			FrameOptions:          "DENY",
+			ReferrerPolicy:        "strict-origin-when-cross-origin",
+			ContentSecurityPolicy: "default-src 'none'; img-src 'self'; frame-ancestors 'none'; base-uri 'none'; form-action 'none'",
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Store:   "redis",
+			Public:  RateLimit{Limit: 120, Window: time.Minute},
+			Auth:    RateLimit{Limit: 20, Window: time.Minute},
+			Admin:   RateLimit{Limit: 300, Window: time.Minute},
+			Upload:  RateLimit{Limit: 30, Window: time.Hour},
+		},
+		Uploads: UploadsConfig{Dir: filepath.Join("public", "uploads")},
+		Log: LogConfig{
+			Level:          "debug",
+			Format:         pkg.LogFormatJSON,
+			File:           filepath.Join("logs", "app.log"),
+			MaxSizeMB:      100,
+			RotateInterval: 24 * time.Hour,
+			MaxBackups:     7,
+			Compress:       true,
+			RedactKeys:     append([]string{}, pkg.DefaultRedactKeys...),
+			HashEmails:     true,
+		},
+		Metrics: MetricsConfig{
+			Enabled: true,
+			Path:    "/metrics",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "http://localhost:4318",
+			ServiceName: "blogku",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// DSN returns the MySQL data source name of the application pool
+func (d DatabaseConfig) DSN() string {
+	return fmt.Sprintf("%s:%s@tcp(%s)/%s?parseTime=true",
+		d.User, d.Password, net.JoinHostPort(d.Host, strconv.Itoa(d.Port)), d.Name)
+}
+
+// MigrateDSN returns the data source name used to apply migrations. Migration files hold several
+// statements, so only this connection allows them; the application pool never does.
+func (d DatabaseConfig) MigrateDSN() string {
+	return d.DSN() + "&multiStatements=true"
+}
+
+// MySQL returns the connection settings used by pkg.Connect
+func (d DatabaseConfig) MySQL() pkg.MySQLConfig {
+	return pkg.MySQLConfig{
+		DSN:             d.DSN(),
+		User:            d.User,
+		Host:            d.Host,
+		Port:            d.Port,
+		Name:            d.Name,
+		MaxOpenConns:    d.MaxOpenConns,
+		MaxIdleConns:    d.MaxIdleConns,
+		ConnMaxLifetime: d.ConnMaxLifetime,
+	}
+}
+
+// Migrator returns the connection settings of the pool that applies migrations
+func (d DatabaseConfig) Migrator() pkg.MySQLConfig {
+	cfg := d.MySQL()
+	cfg.DSN = d.MigrateDSN()
+	return cfg
+}
+
+// Addr returns the Redis host:port
+func (r RedisConfig) Addr() string {
+	return net.JoinHostPort(r.Host, strconv.Itoa(r.Port))
+}
+
+// HashConfig returns the argon2id policy
+func (p PasswordConfig) HashConfig() pkg.HashConfig {
+	return pkg.HashConfig{
+		Time:    uint32(p.Time),
+		Memory:  uint32(p.Memory),
+		Threads: uint8(p.Threads),
+		KeyLen:  uint32(p.KeyLen),
+		SaltLen: uint32(p.SaltLen),
+	}
+}
+
+// MailConfig returns the mailer settings used by pkg.NewMailer
+func (m MailConfig) MailConfig() pkg.MailConfig {
+	return pkg.MailConfig{
+		Driver:       m.Driver,
+		From:         m.From,
+		Dir:          m.Dir,
+		SMTPHost:     m.SMTPHost,
+		SMTPPort:     strconv.Itoa(m.SMTPPort),
+		SMTPUsername: m.SMTPUsername,
+		SMTPPassword: m.SMTPPassword,
+	}
+}
+
+// CookieConfig returns the cookie attributes used by the auth handlers
+func (c CookieConfig) CookieConfig() pkg.CookieConfig {
+	sameSite, _ := pkg.ParseSameSite(c.SameSite)
+	return pkg.CookieConfig{
+		Secure:   c.Secure,
+		SameSite: sameSite,
+		Domain:   c.Domain,
+		Path:     "/",
+	}
+}
+
+// CORSConfig returns the CORS policy used by the CORS middleware
+func (c CORSConfig) CORSConfig() pkg.CORSConfig {
+	return pkg.CORSConfig{
+		AllowedOrigins:   c.AllowedOrigins,
+		AllowedMethods:   c.AllowedMethods,
+		AllowedHeaders:   c.AllowedHeaders,
+		ExposedHeaders:   c.ExposedHeaders,
+		MaxAge:           c.MaxAge,
+		AllowCredentials: c.AllowCredentials,
+	}
+}
+
+// SecurityHeadersConfig returns the headers used by the security headers middleware
+func (s SecurityConfig) SecurityHeadersConfig() pkg.SecurityHeadersConfig {
+	return pkg.SecurityHeadersConfig{
+		HSTSMaxAge:            s.HSTSMaxAge,
+		HSTSIncludeSubdomains: s.HSTSIncludeSubdomains,
+		FrameOptions:          s.FrameOptions,
+		ReferrerPolicy:        s.ReferrerPolicy,
+		ContentSecurityPolicy: s.ContentSecurityPolicy,
+	}
+}
+
+// TracingConfig returns the tracer settings used by pkg.InitTracing
+func (t TracingConfig) TracingConfig() pkg.TracingConfig {
+	return pkg.TracingConfig{
+		Exporter:    strings.ToLower(t.Exporter),
+		Endpoint:    t.Endpoint,
+		ServiceName: t.ServiceName,
+		SampleRatio: t.SampleRatio,
+	}
+}
+
+// LoggerConfig returns the logger settings used by pkg.InitLogger
+func (l LogConfig) LoggerConfig() pkg.LoggerConfig {
+	level, _ := parseLogLevel(l.Level)
+	return pkg.LoggerConfig{
+		Level:  level,
+		Format: strings.ToLower(l.Format),
+		File:   l.File,
+		Rotation: pkg.LogRotation{
+			MaxSize:    int64(l.MaxSizeMB) * 1024 * 1024,
+			Interval:   l.RotateInterval,
+			MaxBackups: l.MaxBackups,
+			Compress:   l.Compress,
+		},
+		Redaction: pkg.RedactionConfig{
+			Keys:       l.RedactKeys,
+			HashEmails: l.HashEmails,
+			HashIPs:    l.HashIPs,
+			HashKey:    l.HashKey,
+		},
+	}
+}
+
+func parseLogLevel(name string) (int, bool) {
+	switch strings.ToLower(name) {
+	case "error":
+		return pkg.LevelError, true
+	case "warn", "warning":
+		return pkg.LevelWarn, true
+	case "info":
+		return pkg.LevelInfo, true
+	case "debug":
+		return pkg.LevelDebug, true
+	default:
+		return pkg.LevelDebug, false
+	}
+}
+
+// sameSiteNone reports whether the cookie config asks for SameSite=None
+func (c CookieConfig) sameSiteNone() bool {
+	sameSite, ok := pkg.ParseSameSite(c.SameSite)
+	return ok && sameSite == http.SameSiteNoneMode
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFile is read when CONFIG_FILE is not set and the file exists
+const defaultConfigFile = "config.yaml"
+
+// ValidationError lists every problem found in the configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Load builds the configuration from defaults, the YAML file named by CONFIG_FILE (or ./config.yaml),
+// .env and the environment, then validates it. Environment variables win over the YAML file.
+// All problems are reported together in a *ValidationError.
+func Load() (*Config, error) {
+	cfg, err := Read()
+	if err != nil {
+		return nil, err
+	}
+	if problems := cfg.Validate(); len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// Read builds the configuration like Load without validating it, for tools that only need some sections
+func Read() (*Config, error) {
+	// .env never overrides variables that are already set
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read .env: %w", err)
+	}
+
+	cfg := Default()
+
+	path, explicit := os.LookupEnv("CONFIG_FILE")
+	if !explicit {
+		path = defaultConfigFile
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := yaml.Unmarshal(data, &cfg); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+		case explicit || !errors.Is(err, os.ErrNotExist):
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+	}
+
+	if problems := applyEnv(reflect.ValueOf(&cfg).Elem(), ""); len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	cfg.applyDerived()
+
+	return &cfg, nil
+}
+
+// applyDerived fills settings that default to other settings
+func (c *Config) applyDerived() {
+	c.App.URL = strings.TrimRight(c.App.URL, "/")
+	if len(c.CORS.AllowedOrigins) == 0 && c.App.URL != "" {
+		c.CORS.AllowedOrigins = []string{c.App.URL}
+	}
+	for i, method := range c.CORS.AllowedMethods {
+		c.CORS.AllowedMethods[i] = strings.ToUpper(method)
+	}
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// applyEnv overrides fields tagged with env:"NAME" from the environment.
+// Empty values are ignored unless the tag has the allowempty option.
+func applyEnv(v reflect.Value, prefix string) []string {
+	problems := []string{}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		value := v.Field(i)
+		name := prefix + strings.Split(field.Tag.Get("yaml"), ",")[0]
+
+		tag := field.Tag.Get("env")
+		if tag == "" {
+			if field.Type.Kind() == reflect.Struct {
+				problems = append(problems, applyEnv(value, name+".")...)
+			}
+			continue
+		}
+
+		envName, option, _ := strings.Cut(tag, ",")
+		raw, ok := os.LookupEnv(envName)
+		if !ok || (raw == "" && option != "allowempty") {
+			continue
+		}
+		if err := setField(value, raw); err != nil {
+			problems = append(problems, fmt.Sprintf("%s (%s): %v", name, envName, err))
+		}
+	}
+
+	return problems
+}
+
+// setField parses raw into the field according to its type
+func setField(value reflect.Value, raw string) error {
+	if value.Addr().Type().Implements(textUnmarshalerType) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+
+	switch {
+	case value.Type() == durationType:
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		value.SetInt(int64(duration))
+	case value.Kind() == reflect.String:
+		value.SetString(raw)
+	case value.Kind() == reflect.Int:
+		number, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		value.SetInt(int64(number))
+	case value.Kind() == reflect.Float64:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		value.SetFloat(number)
+	case value.Kind() == reflect.Bool:
+		flag, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		value.SetBool(flag)
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.String:
+		value.Set(reflect.ValueOf(splitList(raw)))
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
+
+// splitList splits a comma separated list, dropping empty entries
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
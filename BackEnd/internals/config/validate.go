@@ -0,0 +1,238 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/redha28/blogku/pkg"
+)
+
+// Validate returns a message for every invalid setting
+func (c *Config) Validate() []string {
+	problems := []string{}
+	add := func(field, env, format string, args ...any) {
+		problems = append(problems, fmt.Sprintf("%s (%s) %s", field, env, fmt.Sprintf(format, args...)))
+	}
+
+	if _, _, err := net.SplitHostPort(c.Server.Addr); err != nil {
+		add("server.addr", "SERVER_ADDR", "must be host:port, got %q", c.Server.Addr)
+	}
+	if c.Server.ReadHeaderTimeout <= 0 {
+		add("server.read_header_timeout", "SERVER_READ_HEADER_TIMEOUT", "must be positive")
+	}
+	if c.Server.IdleTimeout <= 0 {
+		add("server.idle_timeout", "SERVER_IDLE_TIMEOUT", "must be positive")
+	}
+	if c.Server.DrainDelay < 0 {
+		add("server.drain_delay", "SERVER_DRAIN_DELAY", "must not be negative")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		add("server.shutdown_timeout", "SERVER_SHUTDOWN_TIMEOUT", "must be positive")
+	}
+	if _, err := pkg.ParseTrustedProxies(c.Server.TrustedProxies); err != nil {
+		add("server.trusted_proxies", "SERVER_TRUSTED_PROXIES", "%v", err)
+	}
+
+	if parsed, err := url.Parse(c.App.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		add("app.url", "APP_URL", "must be an absolute http(s) URL, got %q", c.App.URL)
+	}
+	if c.App.TOTPIssuer == "" {
+		add("app.totp_issuer", "TOTP_ISSUER", "is required")
+	}
+
+	if c.Database.Name == "" {
+		add("database.name", "DBNAME", "is required")
+	}
+	if c.Database.Host == "" {
+		add("database.host", "DBHOST", "is required")
+	}
+	if !validPort(c.Database.Port) {
+		add("database.port", "DBPORT", "must be between 1 and 65535")
+	}
+	if c.Database.MaxOpenConns < 1 {
+		add("database.max_open_conns", "DB_MAX_OPEN_CONNS", "must be at least 1")
+	}
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		add("database.max_idle_conns", "DB_MAX_IDLE_CONNS", "must be between 0 and max_open_conns")
+	}
+	if c.Database.ConnMaxLifetime < 0 {
+		add("database.conn_max_lifetime", "DB_CONN_MAX_LIFETIME", "must not be negative")
+	}
+	if c.Database.MigrateLockTimeout < time.Second {
+		add("database.migrate_lock_timeout", "DB_MIGRATE_LOCK_TIMEOUT", "must be at least 1s")
+	}
+
+	if c.Redis.Host == "" {
+		add("redis.host", "RDSHOST", "is required")
+	}
+	if !validPort(c.Redis.Port) {
+		add("redis.port", "RDSPORT", "must be between 1 and 65535")
+	}
+	if c.Redis.DB < 0 {
+		add("redis.db", "RDSDB", "must not be negative")
+	}
+
+	if c.JWT.Secret == "" && c.JWT.KeysFile == "" {
+		add("jwt.secret", "JWT_SECRET", "or jwt.keys_file (JWT_KEYS_FILE) is required")
+	}
+	if c.JWT.KeysFile != "" {
+		if _, err := os.Stat(c.JWT.KeysFile); err != nil {
+			add("jwt.keys_file", "JWT_KEYS_FILE", "cannot be read: %v", err)
+		}
+	}
+
+	if c.Audit.HashKey == "" {
+		add("audit.hash_key", "AUDIT_HASH_KEY", "is required")
+	}
+
+	if c.Password.Time < 1 {
+		add("password.time", "ARGON2_TIME", "must be at least 1")
+	}
+	if c.Password.Threads < 1 || c.Password.Threads > 255 {
+		add("password.threads", "ARGON2_THREADS", "must be between 1 and 255")
+	}
+	if c.Password.Memory < 8*c.Password.Threads {
+		add("password.memory", "ARGON2_MEMORY", "must be at least 8 KiB per thread")
+	}
+	if c.Password.KeyLen < 16 {
+		add("password.key_len", "ARGON2_KEYLEN", "must be at least 16")
+	}
+	if c.Password.SaltLen < 8 {
+		add("password.salt_len", "ARGON2_SALTLEN", "must be at least 8")
+	}
+
+	if c.Login.FailureWindow <= 0 {
+		add("login.failure_window", "LOGIN_FAILURE_WINDOW", "must be positive")
+	}
+	if c.Login.IdentifierLimit < 1 {
+		add("login.identifier_limit", "LOGIN_IDENTIFIER_LIMIT", "must be at least 1")
+	}
+	if c.Login.IPLimit < 1 {
+		add("login.ip_limit", "LOGIN_IP_LIMIT", "must be at least 1")
+	}
+	if c.Login.BaseLockout <= 0 {
+		add("login.base_lockout", "LOGIN_BASE_LOCKOUT", "must be positive")
+	}
+	if c.Login.MaxLockout < c.Login.BaseLockout {
+		add("login.max_lockout", "LOGIN_MAX_LOCKOUT", "must not be shorter than login.base_lockout")
+	}
+
+	switch c.Mail.Driver {
+	case "file":
+		if c.Mail.Dir == "" {
+			add("mail.dir", "MAIL_DIR", "is required for the file driver")
+		}
+	case "smtp":
+		if c.Mail.SMTPHost == "" {
+			add("mail.smtp_host", "SMTP_HOST", "is required for the smtp driver")
+		}
+		if !validPort(c.Mail.SMTPPort) {
+			add("mail.smtp_port", "SMTP_PORT", "must be between 1 and 65535")
+		}
+	default:
+		add("mail.driver", "MAIL_DRIVER", "must be file or smtp, got %q", c.Mail.Driver)
+	}
+	if c.Mail.From == "" {
+		add("mail.from", "MAIL_FROM", "is required")
+	}
+
+	if _, ok := pkg.ParseSameSite(c.Cookie.SameSite); !ok {
+		add("cookie.same_site", "COOKIE_SAMESITE", "must be lax, strict or none, got %q", c.Cookie.SameSite)
+	}
+	// Browsers reject SameSite=None cookies that are not Secure
+	if c.Cookie.sameSiteNone() && !c.Cookie.Secure {
+		add("cookie.secure", "COOKIE_SECURE", "must be true when cookie.same_site is none")
+	}
+
+	if len(c.CORS.AllowedOrigins) == 0 {
+		add("cors.allowed_origins", "CORS_ALLOWED_ORIGINS", "must list at least one origin")
+	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		// Reflecting every origin with credentials would let any site act as the logged in admin
+		if origin == "*" && c.CORS.AllowCredentials {
+			add("cors.allowed_origins", "CORS_ALLOWED_ORIGINS", "cannot be * while cors.allow_credentials is true")
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		add("cors.max_age", "CORS_MAX_AGE", "must not be negative")
+	}
+
+	if c.Security.HSTSMaxAge < 0 {
+		add("security.hsts_max_age", "HSTS_MAX_AGE", "must not be negative")
+	}
+
+	switch strings.ToLower(c.RateLimit.Store) {
+	case "redis", "memory":
+	default:
+		add("rate_limit.store", "RATE_LIMIT_STORE", "must be redis or memory, got %q", c.RateLimit.Store)
+	}
+	for _, policy := range []struct {
+		name  string
+		limit RateLimit
+	}{
+		{"public", c.RateLimit.Public},
+		{"auth", c.RateLimit.Auth},
+		{"admin", c.RateLimit.Admin},
+		{"upload", c.RateLimit.Upload},
+	} {
+		if policy.limit.Limit < 1 || policy.limit.Window < time.Second {
+			add("rate_limit."+policy.name, "RATE_LIMIT_"+strings.ToUpper(policy.name),
+				"must allow at least 1 request per window of 1s or more, got %s", policy.limit)
+		}
+	}
+
+	if c.Uploads.Dir == "" {
+		add("uploads.dir", "UPLOAD_DIR", "is required")
+	}
+
+	if _, ok := parseLogLevel(c.Log.Level); !ok {
+		add("log.level", "LOG_LEVEL", "must be error, warn, info or debug, got %q", c.Log.Level)
+	}
+	switch strings.ToLower(c.Log.Format) {
+	case pkg.LogFormatJSON, pkg.LogFormatText:
+	default:
+		add("log.format", "LOG_FORMAT", "must be json or text, got %q", c.Log.Format)
+	}
+	if c.Log.File == "" {
+		add("log.file", "LOG_FILE", "is required")
+	}
+	if c.Log.MaxSizeMB < 0 {
+		add("log.max_size_mb", "LOG_MAX_SIZE_MB", "must not be negative")
+	}
+	if c.Log.RotateInterval < 0 {
+		add("log.rotate_interval", "LOG_ROTATE_INTERVAL", "must not be negative")
+	}
+	if c.Log.MaxBackups < 0 {
+		add("log.max_backups", "LOG_MAX_BACKUPS", "must not be negative")
+	}
+
+	if c.Metrics.Enabled && !strings.HasPrefix(c.Metrics.Path, "/") {
+		add("metrics.path", "METRICS_PATH", "must start with /, got %q", c.Metrics.Path)
+	}
+
+	switch strings.ToLower(c.Tracing.Exporter) {
+	case "otlp":
+		if parsed, err := url.Parse(c.Tracing.Endpoint); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			add("tracing.endpoint", "OTEL_EXPORTER_OTLP_ENDPOINT", "must be an absolute http(s) URL for the otlp exporter, got %q", c.Tracing.Endpoint)
+		}
+	case "stdout", "none":
+	default:
+		add("tracing.exporter", "TRACING_EXPORTER", "must be otlp, stdout or none, got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.ServiceName == "" {
+		add("tracing.service_name", "OTEL_SERVICE_NAME", "is required")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		add("tracing.sample_ratio", "TRACING_SAMPLE_RATIO", "must be between 0 and 1")
+	}
+
+	return problems
+}
+
+func validPort(port int) bool {
+	return port >= 1 && port <= 65535
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// validConfig returns the defaults completed with the settings that have no default
+func validConfig() Config {
+	cfg := Default()
+	cfg.Database.Name = "blogku"
+	cfg.JWT.Secret = "secret"
+	cfg.Audit.HashKey = "audit-secret"
+	cfg.CORS.AllowedOrigins = []string{cfg.App.URL}
+	return cfg
+}
+
+func TestValidateDefaults(t *testing.T) {
+	cfg := validConfig()
+	if problems := cfg.Validate(); len(problems) != 0 {
+		t.Fatalf("Validate() = %q, want no problems", problems)
+	}
+
+	cfg = Default()
+	problems := cfg.Validate()
+	for _, field := range []string{"database.name (DBNAME)", "jwt.secret (JWT_SECRET)", "audit.hash_key (AUDIT_HASH_KEY)", "cors.allowed_origins (CORS_ALLOWED_ORIGINS)"} {
+		if !containsProblem(problems, field) {
+			t.Errorf("Validate() on the bare defaults = %q, want a problem for %s", problems, field)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+		want   string
+	}{
+		{
+			name:   "server address without port",
+			modify: func(cfg *Config) { cfg.Server.Addr = "localhost" },
+			want:   "server.addr (SERVER_ADDR) must be host:port",
+		},
+		{
+			name:   "trusted proxy that is not an address",
+			modify: func(cfg *Config) { cfg.Server.TrustedProxies = []string{"proxy.internal"} },
+			want:   "server.trusted_proxies (SERVER_TRUSTED_PROXIES)",
+		},
+		{
+			name:   "relative app URL",
+			modify: func(cfg *Config) { cfg.App.URL = "localhost:3000" },
+			want:   "app.url (APP_URL) must be an absolute http(s) URL",
+		},
+		{
+			name:   "more idle than open connections",
+			modify: func(cfg *Config) { cfg.Database.MaxIdleConns = cfg.Database.MaxOpenConns + 1 },
+			want:   "database.max_idle_conns (DB_MAX_IDLE_CONNS)",
+		},
+		{
+			name:   "short migration lock timeout",
+			modify: func(cfg *Config) { cfg.Database.MigrateLockTimeout = time.Millisecond },
+			want:   "database.migrate_lock_timeout (DB_MIGRATE_LOCK_TIMEOUT) must be at least 1s",
+		},
+		{
+			name:   "redis port out of range",
+			modify: func(cfg *Config) { cfg.Redis.Port = 70000 },
+			want:   "redis.port (RDSPORT)",
+		},
+		{
+			name: "missing JWT keys file",
+			modify: func(cfg *Config) {
+				cfg.JWT.Secret = ""
+				cfg.JWT.KeysFile = filepath.Join(t.TempDir(), "missing.json")
+			},
+			want: "jwt.keys_file (JWT_KEYS_FILE) cannot be read",
+		},
+		{
+			name:   "argon2 memory below 8 KiB per thread",
+			modify: func(cfg *Config) { cfg.Password.Memory = 8 },
+			want:   "password.memory (ARGON2_MEMORY)",
+		},
+		{
+			name:   "max lockout shorter than base lockout",
+			modify: func(cfg *Config) { cfg.Login.MaxLockout = time.Second },
+			want:   "login.max_lockout (LOGIN_MAX_LOCKOUT)",
+		},
+		{
+			name:   "unknown mail driver",
+			modify: func(cfg *Config) { cfg.Mail.Driver = "sendmail" },
+			want:   `mail.driver (MAIL_DRIVER) must be file or smtp, got "sendmail"`,
+		},
+		{
+			name:   "smtp driver without host",
+			modify: func(cfg *Config) { cfg.Mail.Driver = "smtp" },
+			want:   "mail.smtp_host (SMTP_HOST) is required for the smtp driver",
+		},
+		{
+			name:   "unknown same site mode",
+			modify: func(cfg *Config) { cfg.Cookie.SameSite = "loose" },
+			want:   "cookie.same_site (COOKIE_SAMESITE)",
+		},
+		{
+			name:   "same site none without secure",
+			modify: func(cfg *Config) { cfg.Cookie.SameSite = "none" },
+			want:   "cookie.secure (COOKIE_SECURE) must be true when cookie.same_site is none",
+		},
+		{
+			name:   "wildcard origin with credentials",
+			modify: func(cfg *Config) { cfg.CORS.AllowedOrigins = []string{"*"} },
+			want:   "cors.allowed_origins (CORS_ALLOWED_ORIGINS) cannot be * while cors.allow_credentials is true",
+		},
+		{
+			name:   "unknown rate limit store",
+			modify: func(cfg *Config) { cfg.RateLimit.Store = "memcached" },
+			want:   "rate_limit.store (RATE_LIMIT_STORE)",
+		},
+		{
+			name:   "rate limit window below one second",
+			modify: func(cfg *Config) { cfg.RateLimit.Auth = RateLimit{Limit: 5, Window: time.Millisecond} },
+			want:   "rate_limit.auth (RATE_LIMIT_AUTH)",
+		},
+		{
+			name:   "unknown log level",
+			modify: func(cfg *Config) { cfg.Log.Level = "verbose" },
+			want:   `log.level (LOG_LEVEL) must be error, warn, info or debug, got "verbose"`,
+		},
+		{
+			name:   "metrics path without leading slash",
+			modify: func(cfg *Config) { cfg.Metrics.Path = "metrics" },
+			want:   "metrics.path (METRICS_PATH)",
+		},
+		{
+			name: "otlp exporter with relative endpoint",
+			modify: func(cfg *Config) {
+				cfg.Tracing.Exporter = "otlp"
+				cfg.Tracing.Endpoint = "collector:4318"
+			},
+			want: "tracing.endpoint (OTEL_EXPORTER_OTLP_ENDPOINT)",
+		},
+		{
+			name:   "sample ratio above one",
+			modify: func(cfg *Config) { cfg.Tracing.SampleRatio = 1.5 },
+			want:   "tracing.sample_ratio (TRACING_SAMPLE_RATIO) must be between 0 and 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(&cfg)
+
+			problems := cfg.Validate()
+			if len(problems) != 1 {
+				t.Fatalf("Validate() = %q, want exactly one problem", problems)
+			}
+			if !strings.HasPrefix(problems[0], tt.want) {
+				t.Errorf("Validate() = %q, want it to start with %q", problems[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := validConfig()
+	cfg.Server.IdleTimeout = 0
+	cfg.Redis.DB = -1
+	cfg.Uploads.Dir = ""
+
+	problems := cfg.Validate()
+	if len(problems) != 3 {
+		t.Fatalf("Validate() = %q, want 3 problems", problems)
+	}
+}
+
+// containsProblem reports whether one of the problems starts with prefix
+func containsProblem(problems []string, prefix string) bool {
+	for _, problem := range problems {
+		if strings.HasPrefix(problem, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/internals/models"
+	"github.com/redha28/blogku/internals/repositories"
+	"github.com/redha28/blogku/pkg"
+)
+
+// AdminUserController handles owner-only admin management
+type AdminUserController struct {
+	repository      repositories.AuthRepository
+	auditRepository repositories.AuditRepository
+	mailer          pkg.Mailer
+	appURL          string
+}
+
+// NewAdminUserController creates a new admin user controller linking emails to the frontend at appURL
+func NewAdminUserController(db *sql.DB, mailer pkg.Mailer, appURL string) *AdminUserController {
+	return &AdminUserController{
+		repository:      repositories.NewAuthRepository(db),
+		auditRepository: repositories.NewAuditRepository(db),
+		mailer:          mailer,
+		appURL:          appURL,
+	}
+}
+
+// ListUsers lists admins with pagination
+// @Summary List admins
+// @Description Retrieve all admins with pagination. Owner only.
+// @Tags admin-users
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Success 200 {object} models.AdminUserListResponse
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/users [get]
+func (u *AdminUserController) ListUsers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	users, total, err := u.repository.ListAdmins(c.Request.Context(), page, limit)
+	if err != nil {
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to list admins", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve admins"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AdminUserListResponse{
+		Users: users,
+		Meta: models.MetaPagination{
+			Page:       page,
+			Limit:      limit,
+			TotalPage:  int(math.Ceil(float64(total) / float64(limit))),
+			TotalItems: total,
+		},
+	})
+}
+
+// GetUser retrieves a single admin
+// @Summary Get an admin
+// @Description Retrieve a single admin by ID. Owner only.
+// @Tags admin-users
+// @Produce json
+// @Param id path int true "Admin ID"
+// @Success 200 {object} models.AdminUser
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id} [get]
+func (u *AdminUserController) GetUser(c *gin.Context) {
+	user, ok := u.loadUser(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateUser updates the username, email or role of an admin
+// @Summary Update an admin
+// @Description Update the username, email or role of an admin. The last active owner cannot be demoted. Owner only.
+// @Tags admin-users
+// @Accept json
+// @Produce json
+// @Param id path int true "Admin ID"
+// @Param updateRequest body models.AdminUserUpdate true "Admin Update Request"
+// @Success 200 {object} models.AdminUser
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id} [patch]
+func (u *AdminUserController) UpdateUser(c *gin.Context) {
+	user, ok := u.loadUser(c)
+	if !ok {
+		return
+	}
+
+	var updateRequest models.AdminUserUpdate
+	if err := c.ShouldBindJSON(&updateRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Only check the fields that actually change, otherwise the admin conflicts with itself
+	if updateRequest.Username == user.Username {
+		updateRequest.Username = ""
+	}
+	if updateRequest.Email == user.Email {
+		updateRequest.Email = ""
+	}
+	if updateRequest.Username != "" || updateRequest.Email != "" {
+		exists, err := u.repository.CheckIfAdminExists(c.Request.Context(), updateRequest.Username, updateRequest.Email)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if exists {
+			c.JSON(http.StatusConflict, gin.H{"error": "Username or email already exists"})
+			return
+		}
+	}
+
+	if err := u.repository.UpdateAdmin(c.Request.Context(), user.ID, updateRequest); err != nil {
+		if rejectLastOwner(c, err) {
+			return
+		}
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to update admin", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update admin"})
+		return
+	}
+
+	updated, err := u.repository.GetAdminUser(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve admin"})
+		return
+	}
+
+	u.audit(c, models.AuditAdminUpdate, user.ID, user, updated)
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DisableUser disables an admin so it can no longer log in or use its sessions and API keys
+// @Summary Disable an admin
+// @Description Disable an admin account. Existing sessions and API keys stop working immediately. Owner only.
+// @Tags admin-users
+// @Produce json
+// @Param id path int true "Admin ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/disable [post]
+func (u *AdminUserController) DisableUser(c *gin.Context) {
+	user, ok := u.loadUser(c)
+	if !ok {
+		return
+	}
+
+	if u.rejectSelf(c, user.ID, "You cannot disable your own account") {
+		return
+	}
+	if err := u.repository.SetAdminDisabled(c.Request.Context(), user.ID, true); err != nil {
+		if rejectLastOwner(c, err) {
+			return
+		}
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to disable admin", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable admin"})
+		return
+	}
+
+	u.audit(c, models.AuditAdminDisable, user.ID, nil, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Admin disabled successfully"})
+}
+
+// EnableUser re-enables a disabled admin
+// @Summary Enable an admin
+// @Description Re-enable a disabled admin account. Owner only.
+// @Tags admin-users
+// @Produce json
+// @Param id path int true "Admin ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/enable [post]
+func (u *AdminUserController) EnableUser(c *gin.Context) {
+	user, ok := u.loadUser(c)
+	if !ok {
+		return
+	}
+
+	if err := u.repository.SetAdminDisabled(c.Request.Context(), user.ID, false); err != nil {
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to enable admin", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable admin"})
+		return
+	}
+
+	u.audit(c, models.AuditAdminEnable, user.ID, nil, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Admin enabled successfully"})
+}
+
+// ForcePasswordReset requires an admin to choose a new password and emails a reset link
+// @Summary Force a password reset
+// @Description Block logins and sessions of an admin until the password is reset, and email a reset link. Owner only.
+// @Tags admin-users
+// @Produce json
+// @Param id path int true "Admin ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/force-password-reset [post]
+func (u *AdminUserController) ForcePasswordReset(c *gin.Context) {
+	user, ok := u.loadUser(c)
+	if !ok {
+		return
+	}
+
+	if err := u.repository.SetMustResetPassword(c.Request.Context(), user.ID, true); err != nil {
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to force password reset", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to force password reset"})
+		return
+	}
+
+	u.audit(c, models.AuditAdminReset, user.ID, nil, nil)
+
+	admin := &models.Admin{ID: user.ID, Username: user.Username, Email: user.Email}
+	if err := sendPasswordReset(c.Request.Context(), u.repository, u.mailer, u.appURL, admin); err != nil {
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to send password reset", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Password reset forced but the email could not be sent"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset required and reset link sent"})
+}
+
+// DeleteUser deletes an admin. Their posts are kept without an author.
+// @Summary Delete an admin
+// @Description Delete an admin account. Posts of the admin are kept without an author. Owner only.
+// @Tags admin-users
+// @Produce json
+// @Param id path int true "Admin ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id} [delete]
+func (u *AdminUserController) DeleteUser(c *gin.Context) {
+	user, ok := u.loadUser(c)
+	if !ok {
+		return
+	}
+
+	if u.rejectSelf(c, user.ID, "You cannot delete your own account") {
+		return
+	}
+	if err := u.repository.DeleteAdmin(c.Request.Context(), user.ID); err != nil {
+		if rejectLastOwner(c, err) {
+			return
+		}
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Admin not found"})
+			return
+		}
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to delete admin", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete admin"})
+		return
+	}
+
+	u.audit(c, models.AuditAdminDelete, user.ID, user, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Admin deleted successfully"})
+}
+
+// audit records an action on an admin account
+func (u *AdminUserController) audit(c *gin.Context, action string, id int, before, after any) {
+	recordAudit(c, u.auditRepository, models.AuditEvent{
+		Action:     action,
+		TargetType: models.AuditTargetAdmin,
+		TargetID:   strconv.Itoa(id),
+	}, before, after)
+}
+
+// loadUser loads the admin from the id path parameter, writing the error response on failure
+func (u *AdminUserController) loadUser(c *gin.Context) (models.AdminUser, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid admin ID"})
+		return models.AdminUser{}, false
+	}
+
+	user, err := u.repository.GetAdminUser(c.Request.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Admin not found"})
+			return user, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return user, false
+	}
+
+	return user, true
+}
+
+// rejectSelf stops owners from locking themselves out
+func (u *AdminUserController) rejectSelf(c *gin.Context, id int, message string) bool {
+	if c.GetString("userID") != strconv.Itoa(id) {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": message})
+	return true
+}
+
+// rejectLastOwner answers with a conflict when the repository refused to remove the last active owner,
+// which keeps the site manageable
+func rejectLastOwner(c *gin.Context, err error) bool {
+	if !errors.Is(err, repositories.ErrLastOwner) {
+		return false
+	}
+	c.JSON(http.StatusConflict, gin.H{"error": "At least one active owner is required"})
+	return true
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/internals/models"
+	"github.com/redha28/blogku/internals/repositories"
+	"github.com/redha28/blogku/pkg"
+)
+
+// APIKeyController handles personal API key operations
+type APIKeyController struct {
+	repository repositories.APIKeyRepository
+}
+
+// NewAPIKeyController creates a new API key controller
+func NewAPIKeyController(db *sql.DB) *APIKeyController {
+	return &APIKeyController{
+		repository: repositories.NewAPIKeyRepository(db),
+	}
+}
+
+// CreateAPIKey creates a personal API key for the logged in admin
+// @Summary Create a personal API key
+// @Description Create a named, scoped API key. The key is only returned once.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param apiKeyRequest body models.APIKeyCreate true "API Key Create Request"
+// @Success 201 {object} models.APIKeyCreateResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/api-keys [post]
+func (k *APIKeyController) CreateAPIKey(c *gin.Context) {
+	adminID, err := strconv.Atoi(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+		return
+	}
+
+	var apiKeyRequest models.APIKeyCreate
+	if err := c.ShouldBindJSON(&apiKeyRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, prefix, err := pkg.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate API key"})
+		return
+	}
+
+	var expiresAt *time.Time
+	if apiKeyRequest.ExpiresInDays > 0 {
+		expiry := time.Now().AddDate(0, 0, apiKeyRequest.ExpiresInDays)
+		expiresAt = &expiry
+	}
+
+	id, err := k.repository.Create(c.Request.Context(), adminID, apiKeyRequest.Name, prefix, pkg.HashOpaqueToken(key), apiKeyRequest.Scopes, expiresAt)
+	if err != nil {
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to create API key", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	apiKey, err := k.repository.GetByID(c.Request.Context(), adminID, int(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve API key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIKeyCreateResponse{
+		Key:    key,
+		APIKey: apiKey,
+	})
+}
+
+// ListAPIKeys lists the personal API keys of the logged in admin
+// @Summary List personal API keys
+// @Description List the API keys of the authenticated admin without their secrets
+// @Tags api-keys
+// @Produce json
+// @Success 200 {array} models.APIKey
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/api-keys [get]
+func (k *APIKeyController) ListAPIKeys(c *gin.Context) {
+	adminID, err := strconv.Atoi(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+		return
+	}
+
+	keys, err := k.repository.ListByAdmin(c.Request.Context(), adminID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey revokes a personal API key of the logged in admin
+// @Summary Revoke a personal API key
+// @Description Revoke an API key so it can no longer be used
+// @Tags api-keys
+// @Produce json
+// @Param id path int true "API Key ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/api-keys/{id} [delete]
+func (k *APIKeyController) RevokeAPIKey(c *gin.Context) {
+	adminID, err := strconv.Atoi(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	if err := k.repository.Revoke(c.Request.Context(), adminID, id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/internals/models"
+	"github.com/redha28/blogku/internals/repositories"
+	"github.com/redha28/blogku/pkg"
+)
+
+// AuditController handles reading the audit log
+type AuditController struct {
+	repository repositories.AuditRepository
+}
+
+// NewAuditController creates a new audit controller
+func NewAuditController(db *sql.DB) *AuditController {
+	return &AuditController{
+		repository: repositories.NewAuditRepository(db),
+	}
+}
+
+// recordAudit stores an audit event for the request. The actor defaults to the authenticated admin.
+// Failures are only logged so auditing never breaks the action itself.
+func recordAudit(c *gin.Context, repository repositories.AuditRepository, event models.AuditEvent, before, after any) {
+	if event.ActorID == nil {
+		if id, err := strconv.Atoi(c.GetString("userID")); err == nil {
+			event.ActorID = &id
+		}
+	}
+	event.IP = c.ClientIP()
+	event.UserAgent = c.Request.UserAgent()
+	if len(event.UserAgent) > 512 {
+		event.UserAgent = event.UserAgent[:512]
+	}
+
+	var err error
+	if before != nil {
+		if event.Before, err = json.Marshal(before); err != nil {
+			pkg.LoggerFromContext(c.Request.Context()).Error("Failed to encode audit event", err)
+		}
+	}
+	if after != nil {
+		if event.After, err = json.Marshal(after); err != nil {
+			pkg.LoggerFromContext(c.Request.Context()).Error("Failed to encode audit event", err)
+		}
+	}
+
+	if err := repository.Record(c.Request.Context(), event); err != nil {
+		pkg.LoggerFromContext(c.Request.Context()).ErrorWithFields("Failed to record audit event", err, map[string]any{
+			"action":     event.Action,
+			"targetType": event.TargetType,
+			"targetId":   event.TargetID,
+		})
+	}
+}
+
+// hashAuditIdentifier hashes a login identifier case-insensitively with the audit key, such as id:3f9a2c1b7d4e.
+// The key comes from the configuration, so events of one identifier can be grouped across restarts.
+func hashAuditIdentifier(key []byte, identifier string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.ToLower(identifier)))
+	return "id:" + hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// ListEvents lists audit events
+// @Summary List audit events
+// @Description Retrieve audit events, newest first, filtered by actor, action, target and time range. Owner only.
+// @Tags audit
+// @Produce json
+// @Param actor_id query int false "Actor admin ID"
+// @Param action query string false "Action, e.g. blog.delete"
+// @Param target_type query string false "Target type, e.g. blog"
+// @Param target_id query string false "Target ID"
+// @Param from query string false "Start time (RFC 3339, inclusive)"
+// @Param to query string false "End time (RFC 3339, exclusive)"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Success 200 {object} models.AuditListResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/audit [get]
+func (a *AuditController) ListEvents(c *gin.Context) {
+	var filter models.AuditFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	events, total, err := a.repository.List(c.Request.Context(), filter, page, limit)
+	if err != nil {
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to list audit events", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuditListResponse{
+		Events: events,
+		Meta: models.MetaPagination{
+			Page:       page,
+			Limit:      limit,
+			TotalPage:  int(math.Ceil(float64(total) / float64(limit))),
+			TotalItems: total,
+		},
+	})
+}
+
+// ExportEvents exports audit events as CSV
+// @Summary Export audit events as CSV
+// @Description Download every audit event matching the filters as CSV. Owner only.
+// @Tags audit
+// @Produce text/csv
+// @Param actor_id query int false "Actor admin ID"
+// @Param action query string false "Action, e.g. blog.delete"
+// @Param target_type query string false "Target type, e.g. blog"
+// @Param target_id query string false "Target ID"
+// @Param from query string false "Start time (RFC 3339, inclusive)"
+// @Param to query string false "End time (RFC 3339, exclusive)"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/admin/audit/export [get]
+func (a *AuditController) ExportEvents(c *gin.Context) {
+	var filter models.AuditFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := "audit-" + time.Now().UTC().Format("20060102-150405") + ".csv"
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"id", "created_at", "actor_id", "action", "target_type", "target_id", "before", "after", "ip", "user_agent"})
+
+	err := a.repository.Export(c.Request.Context(), filter, func(event models.AuditEvent) error {
+		actorID := ""
+		if event.ActorID != nil {
+			actorID = strconv.Itoa(*event.ActorID)
+		}
+		return writer.Write([]string{
+			strconv.FormatInt(event.ID, 10),
+			event.CreatedAt.UTC().Format(time.RFC3339),
+			actorID,
+			event.Action,
+			event.TargetType,
+			event.TargetID,
+			csvSafe(string(event.Before)),
+			csvSafe(string(event.After)),
+			event.IP,
+			csvSafe(event.UserAgent),
+		})
+	})
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+	if err != nil {
+		// Headers are already sent, so the download is cut short
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to export audit events", err)
+	}
+}
+
+// csvSafe stops spreadsheet applications from evaluating user controlled values as formulas
+func csvSafe(value string) string {
+	if value == "" {
+		return value
+	}
+	switch value[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashAuditIdentifier(t *testing.T) {
+	key := []byte("audit-secret")
+
+	hash := hashAuditIdentifier(key, "Alice@Example.com")
+	if !strings.HasPrefix(hash, "id:") || len(hash) != len("id:")+12 {
+		t.Fatalf("hashAuditIdentifier() = %q, want id: and 12 hex characters", hash)
+	}
+	if strings.Contains(strings.ToLower(hash), "alice") {
+		t.Errorf("hashAuditIdentifier() = %q reveals the identifier", hash)
+	}
+	if got := hashAuditIdentifier([]byte("audit-secret"), "alice@example.com"); got != hash {
+		t.Errorf("hashAuditIdentifier() = %q for the same key and identifier, want %q", got, hash)
+	}
+	if got := hashAuditIdentifier([]byte("other-secret"), "alice@example.com"); got == hash {
+		t.Errorf("hashAuditIdentifier() = %q under another key, want a different hash", got)
+	}
+}
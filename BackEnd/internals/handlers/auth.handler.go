@@ -1,27 +1,54 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
-	"log"
+	"math"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redha28/blogku/internals/config"
 	"github.com/redha28/blogku/internals/models"
 	"github.com/redha28/blogku/internals/repositories"
 	"github.com/redha28/blogku/pkg"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// mfaPendingTTL is how long a login may wait for its TOTP code
+	mfaPendingTTL = 5 * time.Minute
+	// recoveryCodeCount is the number of recovery codes issued on TOTP enrolment
+	recoveryCodeCount = 10
 )
 
 // AuthController handles authentication operations
 type AuthController struct {
-	repository repositories.AuthRepository
+	repository        repositories.AuthRepository
+	attemptRepository repositories.LoginAttemptRepository
+	auditRepository   repositories.AuditRepository
+	auditHashKey      []byte
+	mailer            pkg.Mailer
+	worker            *pkg.Worker
+	cookies           pkg.CookieConfig
+	appURL            string
+	totpIssuer        string
 }
 
-// NewAuthController creates a new auth controller
-func NewAuthController(db *sql.DB) *AuthController {
+// NewAuthController creates a new auth controller that sends password reset emails on worker
+func NewAuthController(db *sql.DB, rdb *redis.Client, mailer pkg.Mailer, worker *pkg.Worker, cfg *config.Config) *AuthController {
 	return &AuthController{
-		repository: repositories.NewAuthRepository(db),
+		repository:        repositories.NewAuthRepository(db),
+		attemptRepository: repositories.NewLoginAttemptRepository(rdb, cfg.Login),
+		auditRepository:   repositories.NewAuditRepository(db),
+		auditHashKey:      []byte(cfg.Audit.HashKey),
+		mailer:            mailer,
+		worker:            worker,
+		cookies:           cfg.Cookie.CookieConfig(),
+		appURL:            cfg.App.URL,
+		totpIssuer:        cfg.App.TOTPIssuer,
 	}
 }
 
@@ -35,6 +62,7 @@ func NewAuthController(db *sql.DB) *AuthController {
 // @Success 200 {object} models.AdminResponse
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/auth/login [post]
 func (a *AuthController) Login(c *gin.Context) {
@@ -45,76 +73,528 @@ func (a *AuthController) Login(c *gin.Context) {
 		return
 	}
 
-	// Add debugging information
-	log.Printf("Login attempt with email: %s", loginRequest.Email)
+	logger := pkg.LoggerFromContext(c.Request.Context())
+
+	// Reject locked out identifiers and IPs before spending time on argon2
+	if a.rejectLockedOut(c, loginRequest.Email) {
+		return
+	}
 
 	// Get admin using the versatile authentication method
-	admin, hashedPassword, err := a.repository.GetAdminForAuth(loginRequest.Email)
+	admin, hashedPassword, err := a.repository.GetAdminForAuth(c.Request.Context(), loginRequest.Email)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			log.Printf("No user found with identifier: %s", loginRequest.Email)
+			// The identifier is hashed by the logger unless LOG_HASH_EMAILS is disabled
+			logger.DebugWithFields("No admin found for login identifier", map[string]any{"identifier": loginRequest.Email})
+			a.registerFailure(c, loginRequest.Email, nil)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 			return
 		}
-		log.Printf("Database error during auth: %v", err)
+		logger.Error("Database error during auth", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
 
 	// Safety check for nil admin
 	if admin == nil {
-		log.Println("Admin object is nil despite no database error")
+		logger.Error("Admin object is nil despite no database error", nil)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "System error"})
 		return
 	}
 
 	// Use Argon2 to compare passwords
 	hasher := pkg.InitHashConfig()
-	hasher.UseDefaultConfig()
-
-	log.Printf("Comparing password for user: %s (ID: %d)", admin.Username, admin.ID)
+	hasher.UsePolicyConfig()
 
 	match, err := hasher.CompareHashAndPassword(hashedPassword, loginRequest.Password)
 	// Fix: Check if err is nil before calling err.Error()
 	if err != nil {
-		log.Printf("Password comparison error: %v", err)
+		logger.ErrorWithFields("Password comparison failed", err, map[string]any{"adminId": admin.ID})
+		a.registerFailure(c, loginRequest.Email, &admin.ID)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
 	if !match {
-		log.Println("Password does not match")
+		logger.DebugWithFields("Password does not match", map[string]any{"adminId": admin.ID})
+		a.registerFailure(c, loginRequest.Email, &admin.ID)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
+	if err := a.attemptRepository.Reset(c.Request.Context(), loginRequest.Email); err != nil {
+		pkg.LoggerFromContext(c.Request.Context()).Warn("Failed to reset login attempts: " + err.Error())
+	}
+
+	if a.rejectInactive(c, admin) {
+		return
+	}
+
+	// Transparently upgrade hashes created with a weaker policy
+	if hasher.NeedsRehash(hashedPassword) {
+		a.upgradePasswordHash(c.Request.Context(), hasher, admin.ID, loginRequest.Password)
+	}
+
+	// Enrolled admins must complete the login with a TOTP code
+	if admin.TOTPEnabled {
+		payload := pkg.NewPayload(strconv.Itoa(admin.ID), pkg.RoleMFAPending)
+		payload.ExpiresAt = jwt.NewNumericDate(time.Now().Add(mfaPendingTTL))
+		mfaToken, err := payload.GenerateToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.MFAPendingResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		})
+		return
+	}
+
+	a.issueSession(c, admin)
+}
+
+// rejectInactive refuses to log in disabled admins and admins that must reset their password
+func (a *AuthController) rejectInactive(c *gin.Context, admin *models.Admin) bool {
+	if admin.Disabled {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account disabled"})
+		return true
+	}
+	if admin.MustResetPassword {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Password reset required"})
+		return true
+	}
+	return false
+}
+
+// upgradePasswordHash re-hashes a verified password with the current policy.
+// Failures are only logged because the login itself already succeeded.
+func (a *AuthController) upgradePasswordHash(ctx context.Context, hasher *pkg.HashConfig, adminID int, password string) {
+	hashedPass, err := hasher.GenHashedPassword(password)
+	if err != nil {
+		pkg.LoggerFromContext(ctx).Error("Failed to re-hash password", err)
+		return
+	}
+	if err := a.repository.UpdatePasswordHash(ctx, adminID, hashedPass); err != nil {
+		pkg.LoggerFromContext(ctx).Error("Failed to store upgraded password hash", err)
+		return
+	}
+	pkg.LoggerFromContext(ctx).InfoWithFields("Upgraded password hash", map[string]any{
+		"adminId": adminID,
+		"memory":  hasher.Memory,
+		"time":    hasher.Time,
+		"threads": hasher.Threads,
+	})
+}
+
+// issueSession generates a JWT for the admin, sets the auth and CSRF cookies and writes the login response
+func (a *AuthController) issueSession(c *gin.Context, admin *models.Admin) {
+	token, csrfToken, ok := a.startSession(c, admin)
+	if !ok {
+		return
+	}
+
+	recordAudit(c, a.auditRepository, models.AuditEvent{
+		ActorID:    &admin.ID,
+		Action:     models.AuditLogin,
+		TargetType: models.AuditTargetAdmin,
+		TargetID:   strconv.Itoa(admin.ID),
+	}, nil, gin.H{"mfa": admin.TOTPEnabled})
+	pkg.CountLogin(pkg.LoginSuccess)
+
+	c.JSON(http.StatusOK, models.AdminResponse{
+		ID:        admin.ID,
+		Username:  admin.Username,
+		Email:     admin.Email,
+		Token:     token, // still include in response for API clients
+		CSRFToken: csrfToken,
+	})
+}
+
+// startSession signs a session token for the admin and sets the session cookies.
+// On failure it writes the error response and returns false.
+func (a *AuthController) startSession(c *gin.Context, admin *models.Admin) (string, string, bool) {
+	csrfToken, err := pkg.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return "", "", false
+	}
+
 	// Generate JWT token
-	payload := pkg.NewPayload(strconv.Itoa(admin.ID), "admin")
+	payload := pkg.NewPayload(strconv.Itoa(admin.ID), admin.Role)
+	payload.CSRF = csrfToken
+	payload.Version = admin.TokenVersion
 	token, err := payload.GenerateToken()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
-		return
+		return "", "", false
 	}
 
-	// Set cookie with JWT
+	maxAge := int(time.Hour.Seconds() * 24) // 24 hours
+	a.setCookie(c, "authToken", token, maxAge, true)
+	// Readable by the frontend so it can echo it in the X-CSRF-Token header
+	a.setCookie(c, "csrfToken", csrfToken, maxAge, false)
+
+	return token, csrfToken, true
+}
+
+// setCookie sets a cookie with the configured Secure, SameSite and Domain attributes
+func (a *AuthController) setCookie(c *gin.Context, name, value string, maxAge int, httpOnly bool) {
+	c.SetSameSite(a.cookies.SameSite)
 	c.SetCookie(
-		"authToken",
-		token,
-		int(time.Hour.Seconds()*24), // 24 hours
-		"/",
-		"",
-		false, // set to true in production with HTTPS
-		true,  // HTTP only
+		name,
+		value,
+		maxAge,
+		a.cookies.Path,
+		a.cookies.Domain,
+		a.cookies.Secure,
+		httpOnly,
 	)
+}
 
-	c.JSON(http.StatusOK, models.AdminResponse{
-		ID:       admin.ID,
-		Username: admin.Username,
-		Email:    admin.Email,
-		Token:    token, // still include in response for API clients
+// VerifyMFA completes a login by exchanging an mfa pending token and a code
+// @Summary Complete two-factor login
+// @Description Exchange the mfa pending token from login and a TOTP or recovery code for a session
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param verifyRequest body models.MFAVerify true "MFA Verify Request"
+// @Success 200 {object} models.AdminResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/login/mfa [post]
+func (a *AuthController) VerifyMFA(c *gin.Context) {
+	var verifyRequest models.MFAVerify
+	if err := c.ShouldBindJSON(&verifyRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	payload := pkg.NewPayload("", "")
+	if jwtErr := payload.VerifyToken(verifyRequest.MFAToken); jwtErr.Err != nil || payload.Role != pkg.RoleMFAPending {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+
+	adminID, err := strconv.Atoi(payload.Id)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+
+	// Second factor attempts are throttled per admin so codes cannot be guessed
+	mfaIdentifier := "mfa:" + payload.Id
+	if a.rejectLockedOut(c, mfaIdentifier) {
+		return
+	}
+
+	admin, err := a.repository.GetAdminByID(c.Request.Context(), adminID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if a.rejectInactive(c, admin) {
+		return
+	}
+
+	var valid bool
+	if verifyRequest.Code != "" {
+		valid, err = a.checkTOTPCode(c.Request.Context(), adminID, verifyRequest.Code)
+	} else {
+		valid, err = a.useRecoveryCode(c.Request.Context(), adminID, verifyRequest.RecoveryCode)
+	}
+	if err != nil {
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to verify second factor", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !valid {
+		a.registerFailure(c, mfaIdentifier, &adminID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	if err := a.attemptRepository.Reset(c.Request.Context(), mfaIdentifier); err != nil {
+		pkg.LoggerFromContext(c.Request.Context()).Warn("Failed to reset login attempts: " + err.Error())
+	}
+
+	a.issueSession(c, admin)
+}
+
+// rejectLockedOut responds with 429 and Retry-After when the identifier or client IP is locked out
+func (a *AuthController) rejectLockedOut(c *gin.Context, identifier string) bool {
+	retryAfter, err := a.attemptRepository.Check(c.Request.Context(), identifier, c.ClientIP())
+	if err != nil {
+		// Fail open so a Redis outage does not block every login
+		pkg.LoggerFromContext(c.Request.Context()).Warn("Failed to check login attempts: " + err.Error())
+		return false
+	}
+	if retryAfter <= 0 {
+		return false
+	}
+
+	pkg.CountLogin(pkg.LoginLocked)
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, try again later"})
+	return true
+}
+
+// registerFailure counts a failed attempt and records it, plus every lockout it causes, in the audit log
+func (a *AuthController) registerFailure(c *gin.Context, identifier string, adminID *int) {
+	pkg.CountLogin(pkg.LoginFailure)
+	lockouts, err := a.attemptRepository.RegisterFailure(c.Request.Context(), identifier, c.ClientIP())
+	if err != nil {
+		pkg.LoggerFromContext(c.Request.Context()).Warn("Failed to register login attempt: " + err.Error())
+	}
+
+	// The audit log is kept for long and readable by every owner, so it never stores the submitted identifier
+	subject := gin.H{"identifier": hashAuditIdentifier(a.auditHashKey, identifier)}
+	if adminID != nil {
+		subject = gin.H{"adminId": *adminID}
+	}
+
+	recordAudit(c, a.auditRepository, models.AuditEvent{
+		Action:     models.AuditLoginFailed,
+		TargetType: models.AuditTargetLogin,
+	}, nil, subject)
+
+	for _, lockout := range lockouts {
+		after := gin.H{
+			"scope":    lockout.Scope,
+			"failures": lockout.Failures,
+			"duration": lockout.Duration.String(),
+		}
+		if lockout.Scope == repositories.LockoutScopeIP {
+			after["key"] = lockout.Key
+		} else {
+			for field, value := range subject {
+				after[field] = value
+			}
+		}
+		recordAudit(c, a.auditRepository, models.AuditEvent{
+			Action:     models.AuditLoginLockout,
+			TargetType: models.AuditTargetLogin,
+		}, nil, after)
+	}
+}
+
+// EnrollMFA generates a new TOTP secret for the logged in admin
+// @Summary Start TOTP enrolment
+// @Description Generate a TOTP secret and provisioning URI that must be confirmed with a code
+// @Tags auth
+// @Produce json
+// @Success 200 {object} models.MFAEnrollResponse
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/mfa/enroll [post]
+func (a *AuthController) EnrollMFA(c *gin.Context) {
+	adminID, err := strconv.Atoi(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+		return
+	}
+
+	admin, err := a.repository.GetAdminByID(c.Request.Context(), adminID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if admin.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := pkg.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+
+	if err := a.repository.SetPendingTOTPSecret(c.Request.Context(), adminID, secret); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MFAEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: pkg.TOTPProvisioningURI(a.totpIssuer, admin.Email, secret),
+	})
+}
+
+// ConfirmMFA enables TOTP after the admin proves possession of the secret
+// @Summary Confirm TOTP enrolment
+// @Description Confirm the pending TOTP secret with a code and receive one-time recovery codes
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param confirmRequest body models.MFACode true "MFA Code"
+// @Success 200 {object} models.MFAConfirmResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/mfa/confirm [post]
+func (a *AuthController) ConfirmMFA(c *gin.Context) {
+	adminID, err := strconv.Atoi(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+		return
+	}
+
+	var confirmRequest models.MFACode
+	if err := c.ShouldBindJSON(&confirmRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	secret, enabled, err := a.repository.GetTOTP(c.Request.Context(), adminID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if enabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+	if secret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Start enrolment first"})
+		return
+	}
+
+	valid, err := a.checkTOTPCode(c.Request.Context(), adminID, confirmRequest.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	codes, err := pkg.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	hasher := pkg.InitHashConfig()
+	hasher.UsePolicyConfig()
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hash, err := hasher.GenHashedPassword(code)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash recovery codes"})
+			return
+		}
+		hashes = append(hashes, hash)
+	}
+
+	if err := a.repository.EnableTOTP(c.Request.Context(), adminID, hashes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MFAConfirmResponse{
+		Message:       "Two-factor authentication enabled",
+		RecoveryCodes: codes,
 	})
 }
 
+// DisableMFA turns off TOTP for the logged in admin
+// @Summary Disable TOTP
+// @Description Disable two-factor authentication using a current TOTP code
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param disableRequest body models.MFACode true "MFA Code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/mfa/disable [post]
+func (a *AuthController) DisableMFA(c *gin.Context) {
+	adminID, err := strconv.Atoi(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+		return
+	}
+
+	var disableRequest models.MFACode
+	if err := c.ShouldBindJSON(&disableRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	_, enabled, err := a.repository.GetTOTP(c.Request.Context(), adminID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !enabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+
+	valid, err := a.checkTOTPCode(c.Request.Context(), adminID, disableRequest.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	if err := a.repository.DisableTOTP(c.Request.Context(), adminID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// checkTOTPCode validates a TOTP code and rejects codes from an already used time step
+func (a *AuthController) checkTOTPCode(ctx context.Context, adminID int, code string) (bool, error) {
+	secret, _, err := a.repository.GetTOTP(ctx, adminID)
+	if err != nil || secret == "" {
+		return false, err
+	}
+
+	step, ok := pkg.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	return a.repository.UseTOTPStep(ctx, adminID, step)
+}
+
+// useRecoveryCode consumes a matching unused recovery code
+func (a *AuthController) useRecoveryCode(ctx context.Context, adminID int, code string) (bool, error) {
+	codes, err := a.repository.GetUnusedRecoveryCodes(ctx, adminID)
+	if err != nil {
+		return false, err
+	}
+
+	hasher := pkg.InitHashConfig()
+	hasher.UsePolicyConfig()
+	for _, stored := range codes {
+		match, err := hasher.CompareHashAndPassword(stored.CodeHash, code)
+		if err != nil || !match {
+			continue
+		}
+		return a.repository.UseRecoveryCode(ctx, stored.ID)
+	}
+
+	return false, nil
+}
+
 // Logout clears the auth cookie
 // @Summary Admin logout
 // @Description Clear the authentication cookie for the admin user
@@ -123,22 +603,16 @@ func (a *AuthController) Login(c *gin.Context) {
 // @Success 200 {object} map[string]interface{}
 // @Router /auth/logout [post]
 func (a *AuthController) Logout(c *gin.Context) {
-	c.SetCookie(
-		"authToken",
-		"",
-		-1, // expire immediately
-		"/",
-		"",
-		false,
-		true,
-	)
+	// expire immediately
+	a.setCookie(c, "authToken", "", -1, true)
+	a.setCookie(c, "csrfToken", "", -1, false)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
 
 // CreateAdmin creates a new admin user (private endpoint)
 // @Summary Create a new admin
-// @Description Create a new admin user (requires API key)
+// @Description Create a new admin user (requires a login or an API key with the admins:write scope)
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -157,7 +631,7 @@ func (a *AuthController) CreateAdmin(c *gin.Context) {
 	}
 
 	// Check if username or email already exists
-	exists, err := a.repository.CheckIfAdminExists(adminRequest.Username, adminRequest.Email)
+	exists, err := a.repository.CheckIfAdminExists(c.Request.Context(), adminRequest.Username, adminRequest.Email)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
@@ -170,20 +644,34 @@ func (a *AuthController) CreateAdmin(c *gin.Context) {
 
 	// Hash password with Argon2
 	hasher := pkg.InitHashConfig()
-	hasher.UseDefaultConfig()
+	hasher.UsePolicyConfig()
 	hashedPass, err := hasher.GenHashedPassword(adminRequest.Password)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
 		return
 	}
 
+	if adminRequest.Role == "" {
+		adminRequest.Role = models.RoleAdmin
+	}
+
 	// Create new admin using repository
-	id, err := a.repository.CreateAdmin(adminRequest, hashedPass)
+	id, err := a.repository.CreateAdmin(c.Request.Context(), adminRequest, hashedPass)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create admin"})
 		return
 	}
 
+	recordAudit(c, a.auditRepository, models.AuditEvent{
+		Action:     models.AuditAdminCreate,
+		TargetType: models.AuditTargetAdmin,
+		TargetID:   strconv.FormatInt(id, 10),
+	}, nil, gin.H{
+		"username": adminRequest.Username,
+		"email":    adminRequest.Email,
+		"role":     adminRequest.Role,
+	})
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Admin created successfully",
 		"admin": gin.H{
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/internals/models"
+	"github.com/redha28/blogku/internals/repositories"
+	"github.com/redha28/blogku/pkg"
+)
+
+// fakeAuthRepository keeps one admin in memory. Methods a test does not expect panic on the nil interface.
+type fakeAuthRepository struct {
+	repositories.AuthRepository
+	admin models.Admin
+	hash  string
+}
+
+func (r *fakeAuthRepository) GetAdminForAuth(ctx context.Context, identifier string) (*models.Admin, string, error) {
+	admin := r.admin
+	return &admin, r.hash, nil
+}
+
+func (r *fakeAuthRepository) GetAdminByID(ctx context.Context, id int) (*models.Admin, error) {
+	admin := r.admin
+	return &admin, nil
+}
+
+// UpdatePassword behaves like the SQL repository: it clears a forced reset and ends every session
+func (r *fakeAuthRepository) UpdatePassword(ctx context.Context, adminID int, hashedPassword string) error {
+	r.hash = hashedPassword
+	r.admin.MustResetPassword = false
+	r.admin.TokenVersion++
+	return nil
+}
+
+func (r *fakeAuthRepository) UpdatePasswordHash(ctx context.Context, adminID int, hashedPassword string) error {
+	r.hash = hashedPassword
+	return nil
+}
+
+// fakeLoginAttemptRepository never locks anyone out
+type fakeLoginAttemptRepository struct {
+	repositories.LoginAttemptRepository
+}
+
+func (fakeLoginAttemptRepository) Check(ctx context.Context, identifier, ip string) (time.Duration, error) {
+	return 0, nil
+}
+
+func (fakeLoginAttemptRepository) Reset(ctx context.Context, identifier string) error {
+	return nil
+}
+
+// fakeAuditRepository discards audit events
+type fakeAuditRepository struct {
+	repositories.AuditRepository
+}
+
+func (fakeAuditRepository) Record(ctx context.Context, event models.AuditEvent) error {
+	return nil
+}
+
+func TestLoginUpgradesWeakHashWithoutEndingSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ks, err := pkg.LoadKeySet("", "test-secret")
+	if err != nil {
+		t.Fatalf("LoadKeySet() error = %v", err)
+	}
+	pkg.SetKeySet(ks)
+
+	weak := pkg.InitHashConfig()
+	weak.UseConfig(1, 8*1024, 16, 8, 1)
+	weakHash, err := weak.GenHashedPassword("correct horse")
+	if err != nil {
+		t.Fatalf("GenHashedPassword() error = %v", err)
+	}
+
+	policy := pkg.InitHashConfig()
+	policy.UseConfig(1, 16*1024, 32, 16, 1)
+	pkg.SetHashPolicy(*policy)
+	t.Cleanup(func() {
+		defaults := pkg.InitHashConfig()
+		defaults.UseDefaultConfig()
+		pkg.SetHashPolicy(*defaults)
+	})
+
+	repository := &fakeAuthRepository{
+		admin: models.Admin{ID: 7, Username: "alice", Email: "alice@example.com", Role: "owner", TokenVersion: 3},
+		hash:  weakHash,
+	}
+	controller := &AuthController{
+		repository:        repository,
+		attemptRepository: fakeLoginAttemptRepository{},
+		auditRepository:   fakeAuditRepository{},
+		cookies:           pkg.CookieConfig{Path: "/"},
+	}
+	router := gin.New()
+	router.POST("/login", controller.Login)
+
+	body, _ := json.Marshal(models.AdminLogin{Email: "alice", Password: "correct horse"})
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body)))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Login() status = %d, want 200: %s", recorder.Code, recorder.Body.String())
+	}
+
+	if repository.hash == weakHash || policy.NeedsRehash(repository.hash) {
+		t.Errorf("Login() kept the weak hash %q", repository.hash)
+	}
+	if repository.admin.TokenVersion != 3 {
+		t.Errorf("Login() changed the token version to %d, ending the other sessions", repository.admin.TokenVersion)
+	}
+
+	var response models.AdminResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Login() response is not JSON: %v", err)
+	}
+
+	// The auth middleware accepts the token only if it carries the stored token version
+	payload := pkg.NewPayload("", "")
+	if jwtErr := payload.VerifyToken(response.Token); jwtErr.Err != nil {
+		t.Fatalf("VerifyToken() error = %v", jwtErr.Err)
+	}
+	admin, _ := repository.GetAdminByID(context.Background(), 7)
+	if payload.Id != "7" || payload.Version != admin.TokenVersion {
+		t.Errorf("token for admin %s has version %d, want admin 7 with version %d", payload.Id, payload.Version, admin.TokenVersion)
+	}
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/internals/models"
+	"github.com/redha28/blogku/internals/repositories"
+	"github.com/redha28/blogku/internals/utils"
+	"github.com/redha28/blogku/pkg"
+	"github.com/redis/go-redis/v9"
+)
+
+// AuthorController handles author profile operations
+type AuthorController struct {
+	repository     repositories.AuthorRepository
+	blogRepository repositories.BlogRepository
+	uploadDir      string
+}
+
+// NewAuthorController creates a new author controller storing avatars in uploadDir
+func NewAuthorController(db *sql.DB, rdb *redis.Client, uploadDir string) *AuthorController {
+	return &AuthorController{
+		repository:     repositories.NewAuthorRepository(db, rdb),
+		blogRepository: repositories.NewBlogRepository(db, rdb, uploadDir),
+		uploadDir:      uploadDir,
+	}
+}
+
+// GetAuthor retrieves a public author page
+// @Summary Get an author page
+// @Description Retrieve an author profile together with the author's blog posts
+// @Tags authors
+// @Produce json
+// @Param username path string true "Author Username"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Success 200 {object} models.AuthorPageResponse
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /authors/{username} [get]
+func (a *AuthorController) GetAuthor(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	author, err := a.repository.GetByUsername(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Author not found"})
+			return
+		}
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to retrieve author", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve author"})
+		return
+	}
+
+	posts, err := a.blogRepository.GetAllByAuthor(c.Request.Context(), author.ID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve blogs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuthorPageResponse{
+		Author: author,
+		Blogs:  posts.Blogs,
+		Meta:   posts.Meta,
+	})
+}
+
+// GetProfile retrieves the author profile of the logged in admin
+// @Summary Get own author profile
+// @Description Retrieve the author profile of the authenticated admin
+// @Tags authors
+// @Produce json
+// @Success 200 {object} models.Author
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /auth/profile [get]
+func (a *AuthorController) GetProfile(c *gin.Context) {
+	id, err := strconv.Atoi(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+		return
+	}
+
+	author, err := a.repository.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, author)
+}
+
+// UpdateProfile updates the author profile of the logged in admin
+// @Summary Update own author profile
+// @Description Update display name, bio and avatar of the authenticated admin. An empty display_name or bio clears it.
+// @Tags authors
+// @Accept multipart/form-data
+// @Produce json
+// @Param display_name formData string false "Display Name"
+// @Param bio formData string false "Bio"
+// @Param avatar formData file false "Avatar Image"
+// @Success 200 {object} models.Author
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /auth/profile [patch]
+func (a *AuthorController) UpdateProfile(c *gin.Context) {
+	id, err := strconv.Atoi(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+		return
+	}
+
+	var profileRequest models.AuthorProfileUpdate
+	if err := c.ShouldBind(&profileRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	author, err := a.repository.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve profile"})
+		return
+	}
+
+	// Avatars go through the same upload pipeline as blog images. The name changes with every
+	// upload so cached copies of the old avatar are never served in its place, and it is built
+	// from the admin ID so nothing user supplied ends up in the path.
+	uploads := utils.NewUtils(a.uploadDir)
+	var avatarPath string
+	if file, err := c.FormFile("avatar"); err == nil {
+		name := "avatar-" + strconv.Itoa(author.ID) + "-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+		avatarPath, _, err = uploads.FileHandling(c, file, name, "")
+		if err != nil {
+			pkg.LoggerFromContext(c.Request.Context()).Error("Failed to upload avatar", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to upload avatar: " + err.Error()})
+			return
+		}
+	}
+
+	if profileRequest.DisplayName == nil && profileRequest.Bio == nil && avatarPath == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one field (display_name, bio or avatar) must be provided"})
+		return
+	}
+
+	if err := a.repository.UpdateProfile(c.Request.Context(), id, profileRequest, avatarPath); err != nil {
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to update profile", err)
+		// The profile still points at the old avatar, so drop the new one instead
+		uploads.RemoveUpload(c.Request.Context(), avatarPath)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		return
+	}
+
+	// Only now that the profile points at the new avatar can the old one go
+	if avatarPath != "" {
+		uploads.RemoveUpload(c.Request.Context(), author.AvatarPath)
+	}
+
+	author, err = a.repository.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, author)
+}
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"database/sql"
-	"log"
 	"net/http"
 	"strconv"
 
@@ -16,13 +15,17 @@ import (
 
 // BlogController handles blog-related operations
 type BlogController struct {
-	repository repositories.BlogRepository
+	repository      repositories.BlogRepository
+	auditRepository repositories.AuditRepository
+	uploadDir       string
 }
 
-// NewBlogController creates a new blog controller
-func NewBlogController(db *sql.DB, rdb *redis.Client) *BlogController {
+// NewBlogController creates a new blog controller storing images in uploadDir
+func NewBlogController(db *sql.DB, rdb *redis.Client, uploadDir string) *BlogController {
 	return &BlogController{
-		repository: repositories.NewBlogRepository(db, rdb),
+		repository:      repositories.NewBlogRepository(db, rdb, uploadDir),
+		auditRepository: repositories.NewAuditRepository(db),
+		uploadDir:       uploadDir,
 	}
 }
 
@@ -42,50 +45,61 @@ func NewBlogController(db *sql.DB, rdb *redis.Client) *BlogController {
 func (b *BlogController) CreateBlog(c *gin.Context) {
 	// Parse multipart form with 10 MB max memory
 	if err := c.Request.ParseMultipartForm(10 << 20); err != nil {
-		pkg.Error("Failed to parse multipart form", err)
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to parse multipart form", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse form"})
 		return
 	}
 
 	var blogRequest models.BlogRequest
 	if err := c.ShouldBind(&blogRequest); err != nil {
-		pkg.Error("Invalid blog request", err)
+		pkg.LoggerFromContext(c.Request.Context()).Error("Invalid blog request", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	// The authenticated admin becomes the author of the post
+	authorID, err := strconv.Atoi(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+		return
+	}
+
 	// Handle image upload
 	file, err := c.FormFile("image")
 	if err != nil {
-		pkg.Error("Missing image file", err)
+		pkg.LoggerFromContext(c.Request.Context()).Error("Missing image file", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Image is required"})
 		return
 	}
 
-	// Create blog post with image
-	id, slug, err := b.repository.Create(blogRequest, file)
+	// Create blog post with image. The image name is unique per upload, because a new post may reuse
+	// the slug of a deleted one and uploads are cached as immutable.
+	imageName := utils.UniqueUploadName(utils.GenerateSlug(blogRequest.Title))
+	id, slug, err := b.repository.Create(c.Request.Context(), blogRequest, file, imageName, authorID)
 	if err != nil {
-		pkg.Error("Failed to create blog post", err)
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to create blog post", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create blog post: " + err.Error()})
 		return
 	}
-	pkg.GetLogger().InfoWithFields("Uploading image", map[string]any{
+	pkg.LoggerFromContext(c.Request.Context()).InfoWithFields("Uploading image", map[string]any{
 		"fileName": file.Filename,
 		"fileSize": file.Size,
 	})
-	fileName, _, err := utils.NewUtils().FileHandling(c, file, slug, "")
+	fileName, _, err := utils.NewUtils(b.uploadDir).FileHandling(c, file, imageName, "")
 	if err != nil {
-		pkg.Error("Failed to upload image", err)
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to upload image", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload image: " + err.Error()})
 		return
 	}
-	log.Println("[INFO] Image uploaded successfully:", fileName)
-	pkg.GetLogger().InfoWithFields("Blog post created", map[string]any{
+	pkg.LoggerFromContext(c.Request.Context()).Debug("Image uploaded: " + fileName)
+	pkg.LoggerFromContext(c.Request.Context()).InfoWithFields("Blog post created", map[string]any{
 		"id":    id,
 		"slug":  slug,
 		"title": blogRequest.Title,
 	})
 
+	b.auditBlog(c, models.AuditBlogCreate, int(id), nil)
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Blog post created successfully",
 		"blog": gin.H{
@@ -94,6 +108,7 @@ func (b *BlogController) CreateBlog(c *gin.Context) {
 			"content":  blogRequest.Content,
 			"slug":     slug,
 			"imageUrl": fileName,
+			"authorId": authorID,
 		},
 	})
 }
@@ -112,7 +127,7 @@ func (b *BlogController) GetAllBlogs(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 
-	response, err := b.repository.GetAll(page, limit)
+	response, err := b.repository.GetAll(c.Request.Context(), page, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve blogs"})
 		return
@@ -134,7 +149,7 @@ func (b *BlogController) GetAllBlogs(c *gin.Context) {
 func (b *BlogController) GetBlogBySlug(c *gin.Context) {
 	slug := c.Param("slug")
 
-	blog, err := b.repository.GetBySlug(slug)
+	blog, err := b.repository.GetBySlug(c.Request.Context(), slug)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Blog post not found"})
@@ -165,7 +180,18 @@ func (b *BlogController) UpdateBlog(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one field (title or content) must be provided"})
 		return
 	}
-	_, err = b.repository.Update(id, blogRequest)
+
+	before, err := b.repository.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Blog post not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update blog post"})
+		}
+		return
+	}
+
+	_, err = b.repository.Update(c.Request.Context(), id, blogRequest)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Blog post not found"})
@@ -175,6 +201,8 @@ func (b *BlogController) UpdateBlog(c *gin.Context) {
 		return
 	}
 
+	b.auditBlog(c, models.AuditBlogUpdate, id, &before)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Blog post updated successfully"})
 }
 
@@ -186,7 +214,7 @@ func (b *BlogController) DeleteBlog(c *gin.Context) {
 		return
 	}
 
-	_, err = b.repository.Delete(id)
+	before, err := b.repository.GetByID(c.Request.Context(), id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Blog post not found"})
@@ -196,5 +224,39 @@ func (b *BlogController) DeleteBlog(c *gin.Context) {
 		return
 	}
 
+	_, err = b.repository.Delete(c.Request.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Blog post not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete blog post"})
+		}
+		return
+	}
+
+	recordAudit(c, b.auditRepository, models.AuditEvent{
+		Action:     models.AuditBlogDelete,
+		TargetType: models.AuditTargetBlog,
+		TargetID:   strconv.Itoa(id),
+	}, before, nil)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Blog post deleted successfully"})
 }
+
+// auditBlog records a blog change with the post as it is stored after the change
+func (b *BlogController) auditBlog(c *gin.Context, action string, id int, before *models.BlogResponse) {
+	after, err := b.repository.GetByID(c.Request.Context(), id)
+	if err != nil {
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to load blog post for audit", err)
+	}
+
+	var beforeData any
+	if before != nil {
+		beforeData = before
+	}
+	recordAudit(c, b.auditRepository, models.AuditEvent{
+		Action:     action,
+		TargetType: models.AuditTargetBlog,
+		TargetID:   strconv.Itoa(id),
+	}, beforeData, after)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/internals/migrate"
+	"github.com/redha28/blogku/internals/models"
+	"github.com/redha28/blogku/internals/repositories"
+	"github.com/redha28/blogku/migrations"
+	"github.com/redha28/blogku/pkg"
+	"github.com/redis/go-redis/v9"
+)
+
+// healthCheckTimeout bounds each readiness check so a hung dependency cannot stall the probe
+const healthCheckTimeout = 2 * time.Second
+
+// HealthController answers liveness and readiness probes
+type HealthController struct {
+	repository repositories.HealthRepository
+	uploadDir  string
+}
+
+// NewHealthController creates a new health controller
+func NewHealthController(db *sql.DB, rdb *redis.Client, uploadDir string) *HealthController {
+	return &HealthController{
+		repository: repositories.NewHealthRepository(db, rdb),
+		uploadDir:  uploadDir,
+	}
+}
+
+// Liveness reports that the process is running
+// @Summary Liveness probe
+// @Description Always succeeds while the process can serve requests. Dependencies are not checked.
+// @Tags health
+// @Produce json
+// @Success 200 {object} models.HealthResponse
+// @Router /healthz [get]
+func (h *HealthController) Liveness(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, models.HealthResponse{Status: models.HealthOK})
+}
+
+// Readiness reports whether the API can serve traffic
+// @Summary Readiness probe
+// @Description Checks MySQL, Redis, upload storage and pending migrations with per-dependency status and latency. Fails while the server is shutting down.
+// @Tags health
+// @Produce json
+// @Success 200 {object} models.HealthResponse
+// @Failure 503 {object} models.HealthResponse
+// @Router /readyz [get]
+func (h *HealthController) Readiness(c *gin.Context) {
+	checks := map[string]func(ctx context.Context) error{
+		"mysql":      h.repository.PingMySQL,
+		"redis":      h.repository.PingRedis,
+		"uploads":    h.checkUploads,
+		"migrations": h.checkMigrations,
+	}
+
+	response := models.HealthResponse{
+		Status: models.HealthOK,
+		Checks: make(map[string]models.HealthCheck, len(checks)+1),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(ctx context.Context) error) {
+			defer wg.Done()
+			result := runHealthCheck(c.Request.Context(), check)
+			mu.Lock()
+			response.Checks[name] = result
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	if pkg.ShuttingDown() {
+		response.Checks["shutdown"] = models.HealthCheck{Status: models.HealthFail, Error: "server is shutting down"}
+	}
+
+	status := http.StatusOK
+	for name, check := range response.Checks {
+		if check.Status != models.HealthOK {
+			response.Status = models.HealthFail
+			status = http.StatusServiceUnavailable
+			if name != "shutdown" {
+				pkg.LoggerFromContext(c.Request.Context()).Warn("Readiness check " + name + " failed: " + check.Error)
+			}
+		}
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, response)
+}
+
+// runHealthCheck times a single check
+func runHealthCheck(parent context.Context, check func(ctx context.Context) error) models.HealthCheck {
+	ctx, cancel := context.WithTimeout(parent, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := models.HealthCheck{
+		Status:    models.HealthOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = models.HealthFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// checkUploads verifies a file can be created in the upload directory
+func (h *HealthController) checkUploads(_ context.Context) error {
+	if err := os.MkdirAll(h.uploadDir, 0755); err != nil {
+		return fmt.Errorf("upload directory unavailable: %w", err)
+	}
+	file, err := os.CreateTemp(h.uploadDir, ".readyz-*")
+	if err != nil {
+		return fmt.Errorf("upload directory not writable: %w", err)
+	}
+	name := file.Name()
+	file.Close()
+	return os.Remove(name)
+}
+
+// checkMigrations verifies the database schema is at the latest embedded migration and not dirty
+func (h *HealthController) checkMigrations(ctx context.Context) error {
+	known, err := migrate.Load(migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := h.repository.MigrationVersion(ctx)
+	if err != nil {
+		if errors.Is(err, repositories.ErrMigrationsNotTracked) {
+			return errors.New("no migrations have been applied")
+		}
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d failed and left the schema dirty", version)
+	}
+
+	pending := 0
+	for _, migration := range known {
+		if migration.Version > version {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%d migrations pending, schema at version %d", pending, version)
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/internals/models"
+	"github.com/redha28/blogku/internals/repositories"
+	"github.com/redha28/blogku/pkg"
+)
+
+// invitationTTL is how long an invitation link stays valid
+const invitationTTL = 7 * 24 * time.Hour
+
+// InvitationController handles admin invitations
+type InvitationController struct {
+	repository      repositories.InvitationRepository
+	authRepository  repositories.AuthRepository
+	auditRepository repositories.AuditRepository
+	mailer          pkg.Mailer
+	appURL          string
+}
+
+// NewInvitationController creates a new invitation controller linking emails to the frontend at appURL
+func NewInvitationController(db *sql.DB, mailer pkg.Mailer, appURL string) *InvitationController {
+	return &InvitationController{
+		repository:      repositories.NewInvitationRepository(db),
+		authRepository:  repositories.NewAuthRepository(db),
+		auditRepository: repositories.NewAuditRepository(db),
+		mailer:          mailer,
+		appURL:          appURL,
+	}
+}
+
+// CreateInvitation invites a new admin by email
+// @Summary Invite an admin
+// @Description Email a single-use invitation link for the given email and role. Earlier pending invitations for the email are revoked. Owner only.
+// @Tags admin-users
+// @Accept json
+// @Produce json
+// @Param invitationRequest body models.InvitationCreate true "Invitation Create Request"
+// @Success 201 {object} models.AdminInvitation
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/invitations [post]
+func (i *InvitationController) CreateInvitation(c *gin.Context) {
+	inviterID, err := strconv.Atoi(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+		return
+	}
+
+	var invitationRequest models.InvitationCreate
+	if err := c.ShouldBindJSON(&invitationRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if invitationRequest.Role == "" {
+		invitationRequest.Role = models.RoleAdmin
+	}
+
+	exists, err := i.authRepository.CheckIfAdminExists(c.Request.Context(), "", invitationRequest.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if exists {
+		c.JSON(http.StatusConflict, gin.H{"error": "An admin with this email already exists"})
+		return
+	}
+
+	token, err := pkg.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	expiresAt := time.Now().Add(invitationTTL)
+	id, err := i.repository.Create(c.Request.Context(), invitationRequest.Email, invitationRequest.Role, pkg.HashOpaqueToken(token), inviterID, expiresAt)
+	if err != nil {
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to create invitation", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	link := i.appURL + "/accept-invitation?token=" + url.QueryEscape(token)
+	err = i.mailer.Send(pkg.Mail{
+		To:      []string{invitationRequest.Email},
+		Subject: "You have been invited to Blogku",
+		Body: fmt.Sprintf(
+			"Hi,\n\nYou have been invited to join Blogku as %s. Use the link below to choose your username and password. It expires in %d days and can only be used once.\n\n%s\n\nIf you were not expecting this, you can ignore this email.\n",
+			invitationRequest.Role,
+			int(invitationTTL.Hours()/24),
+			link,
+		),
+	})
+	if err != nil {
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to send invitation", err)
+		if err := i.repository.Revoke(c.Request.Context(), int(id)); err != nil {
+			pkg.LoggerFromContext(c.Request.Context()).Error("Failed to revoke unsent invitation", err)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send invitation"})
+		return
+	}
+
+	invitation, err := i.repository.GetByID(c.Request.Context(), int(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve invitation"})
+		return
+	}
+
+	recordAudit(c, i.auditRepository, models.AuditEvent{
+		Action:     models.AuditInvitationSend,
+		TargetType: models.AuditTargetInvitation,
+		TargetID:   strconv.Itoa(invitation.ID),
+	}, nil, invitation)
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// ListInvitations lists pending invitations
+// @Summary List pending invitations
+// @Description List invitations that were neither accepted nor revoked. Owner only.
+// @Tags admin-users
+// @Produce json
+// @Success 200 {array} models.AdminInvitation
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/invitations [get]
+func (i *InvitationController) ListInvitations(c *gin.Context) {
+	invitations, err := i.repository.ListPending(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve invitations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// RevokeInvitation revokes a pending invitation
+// @Summary Revoke an invitation
+// @Description Revoke a pending invitation so its link can no longer be used. Owner only.
+// @Tags admin-users
+// @Produce json
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/invitations/{id} [delete]
+func (i *InvitationController) RevokeInvitation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	if err := i.repository.Revoke(c.Request.Context(), id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully"})
+}
+
+// AcceptInvitation creates the invited admin with a username and password of their choice
+// @Summary Accept an invitation
+// @Description Create the invited admin using the token from the invitation email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param acceptRequest body models.InvitationAccept true "Invitation Accept Request"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/invitations/accept [post]
+func (i *InvitationController) AcceptInvitation(c *gin.Context) {
+	var acceptRequest models.InvitationAccept
+	if err := c.ShouldBindJSON(&acceptRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invitation, err := i.repository.Claim(c.Request.Context(), pkg.HashOpaqueToken(acceptRequest.Token))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired invitation"})
+			return
+		}
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to claim invitation", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	id, status, message := i.createInvitedAdmin(c.Request.Context(), invitation, acceptRequest)
+	if status != http.StatusCreated {
+		// Give the invitee another try with the same link
+		if err := i.repository.Release(c.Request.Context(), invitation.ID); err != nil {
+			pkg.LoggerFromContext(c.Request.Context()).Error("Failed to release invitation", err)
+		}
+		c.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	adminID := int(id)
+	recordAudit(c, i.auditRepository, models.AuditEvent{
+		ActorID:    &adminID,
+		Action:     models.AuditAdminCreate,
+		TargetType: models.AuditTargetAdmin,
+		TargetID:   strconv.Itoa(adminID),
+	}, nil, gin.H{
+		"username":     acceptRequest.Username,
+		"email":        invitation.Email,
+		"role":         invitation.Role,
+		"invitationId": invitation.ID,
+		"invitedBy":    invitation.InvitedBy,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Admin created successfully",
+		"admin": gin.H{
+			"id":       id,
+			"username": acceptRequest.Username,
+			"email":    invitation.Email,
+			"role":     invitation.Role,
+		},
+	})
+}
+
+// createInvitedAdmin creates the admin for a claimed invitation and returns the response status
+func (i *InvitationController) createInvitedAdmin(ctx context.Context, invitation models.AdminInvitation, acceptRequest models.InvitationAccept) (int64, int, string) {
+	exists, err := i.authRepository.CheckIfAdminExists(ctx, acceptRequest.Username, invitation.Email)
+	if err != nil {
+		return 0, http.StatusInternalServerError, "Database error"
+	}
+	if exists {
+		return 0, http.StatusConflict, "Username or email already exists"
+	}
+
+	hasher := pkg.InitHashConfig()
+	hasher.UsePolicyConfig()
+	hashedPass, err := hasher.GenHashedPassword(acceptRequest.Password)
+	if err != nil {
+		return 0, http.StatusInternalServerError, "Failed to hash password"
+	}
+
+	id, err := i.authRepository.CreateAdmin(ctx, models.AdminCreate{
+		Username: acceptRequest.Username,
+		Password: acceptRequest.Password,
+		Email:    invitation.Email,
+		Role:     invitation.Role,
+	}, hashedPass)
+	if err != nil {
+		pkg.LoggerFromContext(ctx).Error("Failed to create invited admin", err)
+		return 0, http.StatusInternalServerError, "Failed to create admin"
+	}
+
+	return id, http.StatusCreated, ""
+}
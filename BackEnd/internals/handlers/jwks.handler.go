@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/pkg"
+)
+
+// JWKSController publishes the public JWT verification keys
+type JWKSController struct{}
+
+// NewJWKSController creates a new JWKS controller
+func NewJWKSController() *JWKSController {
+	return &JWKSController{}
+}
+
+// GetJWKS returns the JSON Web Key Set
+// @Summary JSON Web Key Set
+// @Description Public keys for verifying tokens signed with RS256 or EdDSA. HS256 secrets are never published.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} pkg.JWKS
+// @Failure 500 {object} map[string]interface{}
+// @Router /.well-known/jwks.json [get]
+func (j *JWKSController) GetJWKS(c *gin.Context) {
+	ks, err := pkg.GetKeySet()
+	if err != nil {
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to load JWT key set", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Key set unavailable"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, ks.JWKS())
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/internals/models"
+	"github.com/redha28/blogku/internals/repositories"
+	"github.com/redha28/blogku/pkg"
+)
+
+// passwordResetTTL is how long a password reset link stays valid
+const passwordResetTTL = time.Hour
+
+// passwordResetSendTimeout bounds storing and mailing a reset link after the forgot request has been answered
+const passwordResetSendTimeout = 30 * time.Second
+
+// ForgotPassword emails a single-use password reset link
+// @Summary Request a password reset
+// @Description Email a single-use password reset link. The response is the same whether or not the email is registered.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param forgotRequest body models.PasswordForgot true "Forgot Password Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/auth/password/forgot [post]
+func (a *AuthController) ForgotPassword(c *gin.Context) {
+	var forgotRequest models.PasswordForgot
+	if err := c.ShouldBindJSON(&forgotRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Do not reveal whether the email belongs to an admin
+	response := gin.H{"message": "If the email is registered, a reset link has been sent"}
+
+	admin, err := a.repository.GetAdminByEmail(c.Request.Context(), forgotRequest.Email)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			pkg.LoggerFromContext(c.Request.Context()).Error("Failed to look up admin for password reset", err)
+		}
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	// Send in the background so the response time does not reveal that the email is registered
+	queued := a.worker.Submit(c.Request.Context(), func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, passwordResetSendTimeout)
+		defer cancel()
+		if err := sendPasswordReset(ctx, a.repository, a.mailer, a.appURL, admin); err != nil {
+			pkg.LoggerFromContext(ctx).Error("Failed to send password reset", err)
+		}
+	})
+	if !queued {
+		pkg.LoggerFromContext(c.Request.Context()).WarnWithFields("Dropped password reset, the email queue is full", map[string]any{"adminId": admin.ID})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// sendPasswordReset stores a hashed reset token for the admin and mails a link to the frontend at appURL
+func sendPasswordReset(ctx context.Context, repository repositories.AuthRepository, mailer pkg.Mailer, appURL string, admin *models.Admin) error {
+	token, err := pkg.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(passwordResetTTL)
+	if err := repository.CreatePasswordReset(ctx, admin.ID, pkg.HashOpaqueToken(token), expiresAt); err != nil {
+		return err
+	}
+
+	link := appURL + "/reset-password?token=" + url.QueryEscape(token)
+	return mailer.Send(pkg.Mail{
+		To:      []string{admin.Email},
+		Subject: "Reset your Blogku password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to choose a new password. It expires in %d minutes and can only be used once.\n\n%s\n\nIf you did not request this, you can ignore this email.\n",
+			admin.Username,
+			int(passwordResetTTL.Minutes()),
+			link,
+		),
+	})
+}
+
+// ResetPassword sets a new password using a reset token
+// @Summary Reset a password
+// @Description Set a new password using the token from a password reset email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param resetRequest body models.PasswordReset true "Reset Password Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/password/reset [post]
+func (a *AuthController) ResetPassword(c *gin.Context) {
+	var resetRequest models.PasswordReset
+	if err := c.ShouldBindJSON(&resetRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hasher := pkg.InitHashConfig()
+	hasher.UsePolicyConfig()
+	hashedPass, err := hasher.GenHashedPassword(resetRequest.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	adminID, err := a.repository.ResetPassword(c.Request.Context(), pkg.HashOpaqueToken(resetRequest.Token), hashedPass)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+			return
+		}
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to reset password", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	pkg.LoggerFromContext(c.Request.Context()).InfoWithFields("Password reset", map[string]any{
+		"adminId": adminID,
+	})
+	recordAudit(c, a.auditRepository, models.AuditEvent{
+		ActorID:    &adminID,
+		Action:     models.AuditPasswordReset,
+		TargetType: models.AuditTargetAdmin,
+		TargetID:   strconv.Itoa(adminID),
+	}, nil, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset"})
+}
+
+// ChangePassword changes the password of the logged in admin
+// @Summary Change own password
+// @Description Change the password of the authenticated admin. Every other session is signed out; the response carries a new session token and the session cookies are replaced.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param changeRequest body models.PasswordChange true "Change Password Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/password/change [post]
+func (a *AuthController) ChangePassword(c *gin.Context) {
+	adminID, err := strconv.Atoi(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+		return
+	}
+
+	var changeRequest models.PasswordChange
+	if err := c.ShouldBindJSON(&changeRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// A stolen session must not be able to brute force the current password
+	identifier := "change:" + strconv.Itoa(adminID)
+	if a.rejectLockedOut(c, identifier) {
+		return
+	}
+
+	hashedPassword, err := a.repository.GetPasswordHash(c.Request.Context(), adminID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	hasher := pkg.InitHashConfig()
+	hasher.UsePolicyConfig()
+	match, err := hasher.CompareHashAndPassword(hashedPassword, changeRequest.CurrentPassword)
+	if err != nil || !match {
+		a.registerFailure(c, identifier, &adminID)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	hashedPass, err := hasher.GenHashedPassword(changeRequest.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	if err := a.repository.UpdatePassword(c.Request.Context(), adminID, hashedPass); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		return
+	}
+
+	if err := a.attemptRepository.Reset(c.Request.Context(), identifier); err != nil {
+		pkg.LoggerFromContext(c.Request.Context()).Warn("Failed to reset login attempts: " + err.Error())
+	}
+
+	recordAudit(c, a.auditRepository, models.AuditEvent{
+		Action:     models.AuditPasswordChange,
+		TargetType: models.AuditTargetAdmin,
+		TargetID:   strconv.Itoa(adminID),
+	}, nil, nil)
+
+	// The change signed out every session, including this one, so hand the caller a new one
+	admin, err := a.repository.GetAdminByID(c.Request.Context(), adminID)
+	if err != nil {
+		pkg.LoggerFromContext(c.Request.Context()).Error("Failed to load admin after password change", err)
+		c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully, please log in again"})
+		return
+	}
+	token, csrfToken, ok := a.startSession(c, admin)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Password changed successfully",
+		"token":     token,
+		"csrfToken": csrfToken,
+	})
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	fp "path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// staticContentTypes are the only types served inline. Everything else is downloaded as an attachment.
+var staticContentTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".webp": "image/webp",
+}
+
+// staticCacheControl caches uploads for a year. Upload names are unique per upload (see
+// utils.UniqueUploadName), so a file never changes under the same URL.
+const staticCacheControl = "public, max-age=31536000, immutable"
+
+// StaticController serves uploaded files without directory listings or content type guessing
+type StaticController struct {
+	root string
+}
+
+// NewStaticController creates a static file controller for the given root directory
+func NewStaticController(root string) *StaticController {
+	return &StaticController{
+		root: root,
+	}
+}
+
+// ServeFile serves a single file below the root directory, answering conditional requests with 304
+func (s *StaticController) ServeFile(c *gin.Context) {
+	name := path.Clean("/" + c.Param("filepath"))
+	if name == "/" || strings.HasSuffix(c.Param("filepath"), "/") {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	fullPath := fp.Join(s.root, fp.FromSlash(name))
+	file, err := os.Open(fullPath)
+	if err != nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	contentType, ok := staticContentTypes[strings.ToLower(fp.Ext(name))]
+	if !ok {
+		contentType = "application/octet-stream"
+		c.Header("Content-Disposition", "attachment")
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", staticCacheControl)
+	// http.ServeContent compares If-None-Match against this ETag
+	c.Header("ETag", fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+
+	http.ServeContent(c.Writer, c.Request, "", info.ModTime(), file)
+}
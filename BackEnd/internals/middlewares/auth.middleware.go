@@ -1,21 +1,45 @@
 package middlewares
 
 import (
+	"crypto/subtle"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/internals/repositories"
 	"github.com/redha28/blogku/pkg"
 )
 
-// AuthMiddleware verifies JWT tokens from cookies and authorizes requests
-func AuthMiddleware() gin.HandlerFunc {
+// Authentication methods stored in the "authMethod" context key
+const (
+	AuthMethodCookie = "cookie"
+	AuthMethodBearer = "bearer"
+	AuthMethodAPIKey = "api_key"
+)
+
+// AuthMiddleware authenticates requests with a JWT from the authToken cookie,
+// a JWT sent as "Authorization: Bearer", or a personal API key sent as a bearer token
+func AuthMiddleware(db *sql.DB) gin.HandlerFunc {
+	apiKeys := repositories.NewAPIKeyRepository(db)
+	admins := repositories.NewAuthRepository(db)
+
 	return func(c *gin.Context) {
-		// Get token from cookie
-		token, err := c.Cookie("authToken")
-		if err != nil {
+		token, method := extractToken(c)
+		if token == "" {
 			c.JSON(401, gin.H{"error": "Authentication required"})
 			c.Abort()
 			return
 		}
 
+		if method == AuthMethodAPIKey {
+			if authenticateAPIKey(c, apiKeys, token) && loadAccount(c, admins) {
+				c.Next()
+			}
+			return
+		}
+
 		// Verify token
 		payload := pkg.NewPayload("", "")
 		jwtErr := payload.VerifyToken(token)
@@ -25,9 +49,170 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// MFA pending tokens can only be exchanged at the verify endpoint
+		if payload.Role == pkg.RoleMFAPending {
+			c.JSON(401, gin.H{"error": "Two-factor authentication required"})
+			c.Abort()
+			return
+		}
+
 		// Set user ID for controllers to use
 		c.Set("userID", payload.Id)
 		c.Set("userRole", payload.Role)
+		c.Set("authMethod", method)
+		c.Set("csrfToken", payload.CSRF)
+		c.Set("tokenVersion", payload.Version)
+		if loadAccount(c, admins) {
+			c.Next()
+		}
+	}
+}
+
+// loadAccount rejects deleted, disabled and reset-pending admins and sessions issued before the last password
+// change, and refreshes the role from the database, so these changes take effect before the token expires
+func loadAccount(c *gin.Context, admins repositories.AuthRepository) bool {
+	id, err := strconv.Atoi(c.GetString("userID"))
+	if err != nil {
+		c.JSON(401, gin.H{"error": "Invalid or expired token"})
+		c.Abort()
+		return false
+	}
+
+	admin, err := admins.GetAdminByID(c.Request.Context(), id)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			pkg.LoggerFromContext(c.Request.Context()).Error("Failed to load admin account", err)
+			c.JSON(500, gin.H{"error": "Database error"})
+			c.Abort()
+			return false
+		}
+		c.JSON(401, gin.H{"error": "Invalid or expired token"})
+		c.Abort()
+		return false
+	}
+	if admin.Disabled {
+		c.JSON(401, gin.H{"error": "Account disabled"})
+		c.Abort()
+		return false
+	}
+	if admin.MustResetPassword {
+		c.JSON(401, gin.H{"error": "Password reset required"})
+		c.Abort()
+		return false
+	}
+	if c.GetString("authMethod") != AuthMethodAPIKey && c.GetInt("tokenVersion") != admin.TokenVersion {
+		c.JSON(401, gin.H{"error": "Invalid or expired token"})
+		c.Abort()
+		return false
+	}
+
+	c.Set("userRole", admin.Role)
+	c.Request = c.Request.WithContext(pkg.WithLogAttrs(c.Request.Context(), "userId", admin.ID))
+	return true
+}
+
+// extractToken prefers the Authorization header and falls back to the auth cookie
+func extractToken(c *gin.Context) (string, string) {
+	if header := c.GetHeader("Authorization"); header != "" {
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") {
+			return "", ""
+		}
+		token = strings.TrimSpace(token)
+		if strings.HasPrefix(token, pkg.APIKeyPrefix) {
+			return token, AuthMethodAPIKey
+		}
+		return token, AuthMethodBearer
+	}
+
+	token, err := c.Cookie("authToken")
+	if err != nil {
+		return "", ""
+	}
+	return token, AuthMethodCookie
+}
+
+// authenticateAPIKey validates a personal API key and records its use
+func authenticateAPIKey(c *gin.Context, apiKeys repositories.APIKeyRepository, key string) bool {
+	prefix, ok := pkg.ParseAPIKey(key)
+	if !ok {
+		c.JSON(401, gin.H{"error": "Invalid API key"})
+		c.Abort()
+		return false
+	}
+
+	apiKey, keyHash, err := apiKeys.GetByPrefix(c.Request.Context(), prefix)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			pkg.LoggerFromContext(c.Request.Context()).Error("Failed to look up API key", err)
+		}
+		c.JSON(401, gin.H{"error": "Invalid API key"})
+		c.Abort()
+		return false
+	}
+
+	if subtle.ConstantTimeCompare([]byte(keyHash), []byte(pkg.HashOpaqueToken(key))) != 1 {
+		c.JSON(401, gin.H{"error": "Invalid API key"})
+		c.Abort()
+		return false
+	}
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt)) {
+		c.JSON(401, gin.H{"error": "API key revoked or expired"})
+		c.Abort()
+		return false
+	}
+
+	if err := apiKeys.TouchLastUsed(c.Request.Context(), apiKey.ID); err != nil {
+		pkg.LoggerFromContext(c.Request.Context()).Warn("Failed to record API key use: " + err.Error())
+	}
+
+	c.Set("userID", strconv.Itoa(apiKey.AdminID))
+	c.Set("authMethod", AuthMethodAPIKey)
+	c.Set("apiKeyID", apiKey.ID)
+	c.Set("scopes", apiKey.Scopes)
+	return true
+}
+
+// RequireScope rejects API keys that were not granted the scope. JWT sessions hold every scope.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("authMethod") != AuthMethodAPIKey {
+			c.Next()
+			return
+		}
+
+		for _, granted := range c.GetStringSlice("scopes") {
+			if granted == scope {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(403, gin.H{"error": "API key is missing scope " + scope})
+		c.Abort()
+	}
+}
+
+// RequireRole rejects admins that do not have the role
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("userRole") != role {
+			c.JSON(403, gin.H{"error": "Forbidden"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSession rejects API keys for account management routes that need a real login
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("authMethod") == AuthMethodAPIKey {
+			c.JSON(403, gin.H{"error": "This endpoint cannot be used with an API key"})
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
@@ -1,36 +1,63 @@
 package middlewares
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/pkg"
 )
 
-// CORSMiddleware handles CORS for the application
-func CORSMiddleware() gin.HandlerFunc {
+// CORSMiddleware handles CORS for the application using the given policy
+func CORSMiddleware(cfg pkg.CORSConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "http://localhost:3000") // Update with your frontend URL
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PATCH, DELETE")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		if applyCORS(c, cfg) {
 			return
 		}
-
 		c.Next()
 	}
 }
 
 // CORSMiddleware is the handler function
 func (m *Middleware) CORSMiddleware(c *gin.Context) {
-	c.Writer.Header().Set("Access-Control-Allow-Origin", "http://localhost:3000") // Update with your frontend URL
-	c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-	c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-	c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PATCH, DELETE")
+	if applyCORS(c, m.cors) {
+		return
+	}
+	c.Next()
+}
+
+// applyCORS sets the CORS headers for allowed origins and answers preflight requests.
+// It returns true when the request has been handled.
+func applyCORS(c *gin.Context, cfg pkg.CORSConfig) bool {
+	header := c.Writer.Header()
+	// Responses differ per origin, so caches must not share them
+	header.Add("Vary", "Origin")
+
+	preflight := c.Request.Method == "OPTIONS" && c.GetHeader("Access-Control-Request-Method") != ""
+	if preflight {
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+	}
+
+	allowOrigin, ok := cfg.AllowOrigin(c.GetHeader("Origin"))
+	if ok {
+		header.Set("Access-Control-Allow-Origin", allowOrigin)
+		if cfg.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+		if !preflight && len(cfg.ExposedHeaders) > 0 {
+			header.Set("Access-Control-Expose-Headers", strings.Join(cfg.ExposedHeaders, ", "))
+		}
+		if preflight {
+			header.Set("Access-Control-Allow-Methods", strings.Join(cfg.AllowedMethods, ", "))
+			header.Set("Access-Control-Allow-Headers", strings.Join(cfg.AllowedHeaders, ", "))
+			header.Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAge))
+		}
+	}
 
 	if c.Request.Method == "OPTIONS" {
 		c.AbortWithStatus(204)
-		return
+		return true
 	}
-	c.Next()
+	return false
 }
@@ -1,7 +1,13 @@
 package middlewares
 
-type Middleware struct{}
+import "github.com/redha28/blogku/pkg"
 
-func InitMiddleware() *Middleware {
-	return &Middleware{}
+type Middleware struct {
+	cors pkg.CORSConfig
+}
+
+func InitMiddleware(cors pkg.CORSConfig) *Middleware {
+	return &Middleware{
+		cors: cors,
+	}
 }
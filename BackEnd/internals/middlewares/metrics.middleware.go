@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/pkg"
+)
+
+// MetricsMiddleware records request counts and latency by route template, so /blogs/:slug is one series
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		done := pkg.TrackHTTPRequest()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		done(c.Request.Method, route, c.Writer.Status())
+	}
+}
+
+// MetricsAuthMiddleware requires "Authorization: Bearer <token>" when a token is configured
+func MetricsAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="metrics"`)
+			c.AbortWithStatusJSON(401, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		c.Next()
+	}
+}
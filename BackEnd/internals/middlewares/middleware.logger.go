@@ -25,8 +25,8 @@ func LoggerMiddleware() gin.HandlerFunc {
 		duration := time.Since(startTime)
 		status := c.Writer.Status()
 
-		// Log request details
-		pkg.LogHTTPRequest(method, path, clientIP, status, duration)
+		// Log request details with the logger bound to the request, which knows the user once authenticated
+		pkg.LogHTTPRequest(c.Request.Context(), method, path, clientIP, status, duration)
 	}
 }
 
@@ -44,7 +44,7 @@ func RecoveryMiddleware() gin.HandlerFunc {
 					"stack": stackTrace,
 					"path":  c.Request.URL.Path,
 				}
-				pkg.ErrorWithFields("PANIC RECOVERED", fmt.Errorf("%v", err), fields)
+				pkg.LoggerFromContext(c.Request.Context()).ErrorWithFields("PANIC RECOVERED", fmt.Errorf("%v", err), fields)
 
 				// Return 500 error
 				c.AbortWithStatus(500)
@@ -0,0 +1,276 @@
+package middlewares
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/internals/config"
+	"github.com/redha28/blogku/pkg"
+	"github.com/redis/go-redis/v9"
+)
+
+// Rate limit policy names. Limits are set per policy in config.RateLimitConfig.
+const (
+	RateLimitPublic = "public"
+	RateLimitAuth   = "auth"
+	RateLimitAdmin  = "admin"
+	RateLimitUpload = "upload"
+	// RateLimitAdminIP runs before AuthMiddleware with the admin limit per client IP, so requests with
+	// invalid tokens or API keys cannot flood the account lookups
+	RateLimitAdminIP = "admin_ip"
+)
+
+// RateLimitKey returns the bucket a request is counted in
+type RateLimitKey func(c *gin.Context) string
+
+// RateLimitPolicy allows Limit requests per Window for every key
+type RateLimitPolicy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+	Key    RateLimitKey
+}
+
+// KeyByIP counts requests per client IP
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// KeyByUser counts requests per API key or logged in admin, falling back to the client IP.
+// It must run after AuthMiddleware.
+func KeyByUser(c *gin.Context) string {
+	if id := c.GetInt("apiKeyID"); id != 0 {
+		return "key:" + strconv.Itoa(id)
+	}
+	if id := c.GetString("userID"); id != "" {
+		return "user:" + id
+	}
+	return KeyByIP(c)
+}
+
+// rateLimitResult is the outcome of counting one request
+type rateLimitResult struct {
+	Allowed bool
+	Used    int
+}
+
+// rateLimitStore counts requests with a sliding window
+type rateLimitStore interface {
+	Take(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (rateLimitResult, error)
+}
+
+// RateLimiter applies named rate limit policies backed by Redis, or by memory in single-node mode
+type RateLimiter struct {
+	enabled  bool
+	policies map[string]RateLimitPolicy
+	store    rateLimitStore
+	fallback rateLimitStore
+	// degraded is set while the store fails, so the outage is logged once instead of on every request
+	degraded atomic.Bool
+}
+
+// NewRateLimiter creates a rate limiter from the rate limit configuration.
+// A disabled config turns limiting off and the memory store keeps counters in process.
+// A Redis outage falls back to in-memory counters instead of rejecting or allowing everything.
+func NewRateLimiter(rdb *redis.Client, cfg config.RateLimitConfig) *RateLimiter {
+	limiter := &RateLimiter{
+		enabled:  cfg.Enabled,
+		policies: map[string]RateLimitPolicy{},
+		fallback: newMemoryRateLimitStore(),
+	}
+
+	if strings.ToLower(cfg.Store) == "memory" || rdb == nil {
+		limiter.store = limiter.fallback
+	} else {
+		limiter.store = &redisRateLimitStore{rdb: rdb}
+	}
+
+	for _, policy := range []RateLimitPolicy{
+		{Name: RateLimitPublic, Limit: cfg.Public.Limit, Window: cfg.Public.Window, Key: KeyByIP},
+		{Name: RateLimitAuth, Limit: cfg.Auth.Limit, Window: cfg.Auth.Window, Key: KeyByIP},
+		{Name: RateLimitAdmin, Limit: cfg.Admin.Limit, Window: cfg.Admin.Window, Key: KeyByUser},
+		{Name: RateLimitAdminIP, Limit: cfg.Admin.Limit, Window: cfg.Admin.Window, Key: KeyByIP},
+		{Name: RateLimitUpload, Limit: cfg.Upload.Limit, Window: cfg.Upload.Window, Key: KeyByUser},
+	} {
+		limiter.policies[policy.Name] = policy
+	}
+
+	return limiter
+}
+
+// Policy returns a middleware enforcing the named policy
+func (l *RateLimiter) Policy(name string) gin.HandlerFunc {
+	policy, ok := l.policies[name]
+	if !ok || !l.enabled {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		key := "ratelimit:" + policy.Name + ":" + policy.Key(c)
+
+		result, err := l.store.Take(c.Request.Context(), key, policy.Limit, policy.Window, now)
+		if err != nil {
+			l.storeFailed(c.Request.Context(), err)
+			result, _ = l.fallback.Take(c.Request.Context(), key, policy.Limit, policy.Window, now)
+		} else {
+			l.storeRecovered(c.Request.Context())
+		}
+
+		remaining := policy.Limit - result.Used
+		if remaining < 0 {
+			remaining = 0
+		}
+		reset := windowReset(policy.Window, now)
+
+		c.Header("RateLimit-Policy", strconv.Itoa(policy.Limit)+";w="+strconv.Itoa(int(policy.Window.Seconds())))
+		c.Header("RateLimit-Limit", strconv.Itoa(policy.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(reset))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(reset))
+			c.JSON(429, gin.H{"error": "Too many requests, try again later"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// storeFailed logs the first failure of an outage as a warning and the following ones at debug level
+func (l *RateLimiter) storeFailed(ctx context.Context, err error) {
+	if l.degraded.CompareAndSwap(false, true) {
+		pkg.LoggerFromContext(ctx).Warn("Rate limit store unavailable, using in-memory counters until it recovers: " + err.Error())
+		return
+	}
+	pkg.LoggerFromContext(ctx).Debug("Rate limit store still unavailable: " + err.Error())
+}
+
+// storeRecovered logs the end of an outage once
+func (l *RateLimiter) storeRecovered(ctx context.Context) {
+	if l.degraded.CompareAndSwap(true, false) {
+		pkg.LoggerFromContext(ctx).Info("Rate limit store recovered, using shared counters again")
+	}
+}
+
+// windowReset returns the seconds until the current window ends
+func windowReset(window time.Duration, now time.Time) int {
+	elapsed := time.Duration(now.UnixNano() % int64(window))
+	return int(math.Ceil((window - elapsed).Seconds()))
+}
+
+// windowStart returns the start of the fixed window containing now and how far into it now is, from 0 to 1
+func windowStart(window time.Duration, now time.Time) (int64, float64) {
+	start := now.UnixNano() / int64(window)
+	elapsed := float64(now.UnixNano()%int64(window)) / float64(window)
+	return start, elapsed
+}
+
+// slidingWindowScript increments the current window and weighs in the previous one.
+// Rejected requests are not counted so a client that backs off recovers on schedule.
+var slidingWindowScript = redis.NewScript(`
+local current = redis.call('INCR', KEYS[1])
+if current == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+local used = math.floor(previous * tonumber(ARGV[2]) + current)
+if used > tonumber(ARGV[3]) then
+	redis.call('DECR', KEYS[1])
+	return {0, used - 1}
+end
+return {1, used}
+`)
+
+// redisRateLimitStore shares counters between all API instances
+type redisRateLimitStore struct {
+	rdb *redis.Client
+}
+
+func (s *redisRateLimitStore) Take(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (rateLimitResult, error) {
+	start, elapsed := windowStart(window, now)
+	currentKey := key + ":" + strconv.FormatInt(start, 10)
+	previousKey := key + ":" + strconv.FormatInt(start-1, 10)
+
+	values, err := slidingWindowScript.Run(ctx, s.rdb,
+		[]string{currentKey, previousKey},
+		(2 * window).Milliseconds(),
+		strconv.FormatFloat(1-elapsed, 'f', 4, 64),
+		limit,
+	).Int64Slice()
+	if err != nil {
+		return rateLimitResult{}, err
+	}
+
+	return rateLimitResult{Allowed: values[0] == 1, Used: int(values[1])}, nil
+}
+
+// memoryRateLimitStore keeps counters in process for single-node mode
+type memoryRateLimitStore struct {
+	mu       sync.Mutex
+	counters map[string]*memoryWindow
+	sweepAt  time.Time
+}
+
+type memoryWindow struct {
+	start    int64
+	current  int
+	previous int
+	window   time.Duration
+}
+
+func newMemoryRateLimitStore() *memoryRateLimitStore {
+	return &memoryRateLimitStore{counters: map[string]*memoryWindow{}}
+}
+
+func (s *memoryRateLimitStore) Take(_ context.Context, key string, limit int, window time.Duration, now time.Time) (rateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	start, elapsed := windowStart(window, now)
+	counter, ok := s.counters[key]
+	if !ok {
+		counter = &memoryWindow{start: start, window: window}
+		s.counters[key] = counter
+	}
+	switch {
+	case counter.start == start-1:
+		counter.previous, counter.current, counter.start = counter.current, 0, start
+	case counter.start != start:
+		counter.previous, counter.current, counter.start = 0, 0, start
+	}
+
+	used := int(float64(counter.previous)*(1-elapsed)) + counter.current + 1
+	if used > limit {
+		return rateLimitResult{Allowed: false, Used: used - 1}, nil
+	}
+	counter.current++
+	return rateLimitResult{Allowed: true, Used: used}, nil
+}
+
+// sweep drops counters whose windows have fully expired, at most once a minute
+func (s *memoryRateLimitStore) sweep(now time.Time) {
+	if now.Before(s.sweepAt) {
+		return
+	}
+	s.sweepAt = now.Add(time.Minute)
+
+	for key, counter := range s.counters {
+		start, _ := windowStart(counter.window, now)
+		if counter.start < start-1 {
+			delete(s.counters, key)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package middlewares
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimitStoreTake(t *testing.T) {
+	const limit, window = 3, 10 * time.Second
+	// Unix 1000 starts a 10s window, so offsets below are the elapsed part of the window
+	base := time.Unix(1000, 0)
+
+	steps := []struct {
+		name    string
+		offset  time.Duration
+		allowed bool
+		used    int
+	}{
+		{name: "first request", offset: 0, allowed: true, used: 1},
+		{name: "second request", offset: time.Second, allowed: true, used: 2},
+		{name: "last request within the limit", offset: 2 * time.Second, allowed: true, used: 3},
+		{name: "over the limit", offset: 3 * time.Second, allowed: false, used: 3},
+		{name: "start of the next window counts the whole previous one", offset: 10 * time.Second, allowed: false, used: 3},
+		{name: "half way the previous window weighs half", offset: 15 * time.Second, allowed: true, used: 2},
+		{name: "previous window still weighs one request", offset: 16 * time.Second, allowed: true, used: 3},
+		{name: "previous window weighs less than one request", offset: 17 * time.Second, allowed: true, used: 3},
+		{name: "current window is full", offset: 18 * time.Second, allowed: false, used: 3},
+		{name: "windows later the counters start over", offset: 35 * time.Second, allowed: true, used: 1},
+	}
+
+	store := newMemoryRateLimitStore()
+	for _, step := range steps {
+		result, err := store.Take(context.Background(), "login:192.0.2.1", limit, window, base.Add(step.offset))
+		if err != nil {
+			t.Fatalf("%s: Take() error = %v", step.name, err)
+		}
+		if result.Allowed != step.allowed || result.Used != step.used {
+			t.Errorf("%s: Take() = allowed %v, used %d, want allowed %v, used %d", step.name, result.Allowed, result.Used, step.allowed, step.used)
+		}
+	}
+}
+
+func TestMemoryRateLimitStoreKeys(t *testing.T) {
+	store := newMemoryRateLimitStore()
+	now := time.Unix(1000, 0)
+
+	if result, _ := store.Take(context.Background(), "a", 1, time.Minute, now); !result.Allowed {
+		t.Fatalf("first request of a was rejected")
+	}
+	if result, _ := store.Take(context.Background(), "a", 1, time.Minute, now); result.Allowed {
+		t.Errorf("second request of a was allowed over a limit of 1")
+	}
+	if result, _ := store.Take(context.Background(), "b", 1, time.Minute, now); !result.Allowed {
+		t.Errorf("request of b was rejected because of a")
+	}
+
+	// Counters whose windows have fully passed are swept
+	store.Take(context.Background(), "c", 1, time.Minute, now.Add(3*time.Minute))
+	if _, ok := store.counters["a"]; ok {
+		t.Errorf("expired counter of a was not swept")
+	}
+}
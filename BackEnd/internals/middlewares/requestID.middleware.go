@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/pkg"
+)
+
+// RequestIDHeader carries the request correlation ID in requests and responses
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength caps IDs accepted from clients and proxies
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware reuses a well-formed X-Request-ID from the client or proxy, or generates one,
+// echoes it in the response and binds a logger carrying the request ID, route and trace ID to the request context
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = pkg.GenerateRequestID()
+		}
+		c.Set("requestID", requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		attrs := []any{"requestId", requestID, "route", route}
+		if traceID := pkg.TraceID(c.Request.Context()); traceID != "" {
+			attrs = append(attrs, "traceId", traceID)
+		}
+		c.Request = c.Request.WithContext(pkg.WithLogAttrs(c.Request.Context(), attrs...))
+
+		c.Next()
+	}
+}
+
+// validRequestID accepts short IDs made of characters that are safe to log and echo
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
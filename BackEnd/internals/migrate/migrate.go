@@ -0,0 +1,384 @@
+// Package migrate applies versioned SQL migrations and records the schema version in the
+// schema_migrations table, using the same layout as golang-migrate so existing databases keep working.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// lockName is the MySQL named lock held while migrating so only one replica migrates at a time
+const lockName = "blogku_schema_migrations"
+
+// errNoSuchTable is the MySQL error number for a missing table
+const errNoSuchTable = 1146
+
+// ErrLocked is returned when another process holds the migration lock for longer than the lock timeout
+var ErrLocked = errors.New("another process is running migrations")
+
+// Migration is one versioned schema change
+type Migration struct {
+	Version  int64
+	Name     string
+	upFile   string
+	downFile string
+}
+
+// MigrationStatus reports whether a migration has been applied
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// DirtyError is returned when a previous migration failed part way and the schema needs manual repair
+type DirtyError struct {
+	Version int64
+}
+
+func (e *DirtyError) Error() string {
+	return fmt.Sprintf("schema is dirty at version %d: repair it by hand, then run \"migrate force <version>\"", e.Version)
+}
+
+// rowQuerier is satisfied by *sql.DB and *sql.Conn
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn is satisfied by *sql.Conn so every statement runs in the session holding the lock
+type conn interface {
+	rowQuerier
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// Migrator applies the migrations found in an fs.FS
+type Migrator struct {
+	DB *sql.DB
+	// LockTimeout is how long to wait for another process to finish migrating
+	LockTimeout time.Duration
+
+	fsys       fs.FS
+	migrations []Migration
+}
+
+// New loads the migrations in fsys
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		DB:          db,
+		LockTimeout: time.Minute,
+		fsys:        fsys,
+		migrations:  migrations,
+	}, nil
+}
+
+// Load returns the migrations named <version>_<name>.up.sql and <version>_<name>.down.sql in fsys, ordered by version
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+
+		base, direction, ok := cutDirection(name)
+		if !ok {
+			return nil, fmt.Errorf("migration %s must end in .up.sql or .down.sql", name)
+		}
+		prefix, title, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("migration %s must start with a positive version number", name)
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: title}
+			byVersion[version] = migration
+		} else if migration.Name != title {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", migration.Name, title, version)
+		}
+		if direction == "up" {
+			migration.upFile = name
+		} else {
+			migration.downFile = name
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.upFile == "" {
+			return nil, fmt.Errorf("migration %d_%s has no .up.sql file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// cutDirection splits "001_x.up.sql" into "001_x" and "up"
+func cutDirection(name string) (string, string, bool) {
+	if base, ok := strings.CutSuffix(name, ".up.sql"); ok {
+		return base, "up", true
+	}
+	if base, ok := strings.CutSuffix(name, ".down.sql"); ok {
+		return base, "down", true
+	}
+	return "", "", false
+}
+
+// Migrations returns the known migrations ordered by version
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Latest returns the highest known migration version, or 0 when there are none
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the applied schema version. Version 0 means no migration has been applied.
+func (m *Migrator) Version(ctx context.Context) (int64, bool, error) {
+	return readVersion(ctx, m.DB)
+}
+
+// Status lists every known migration with whether it has been applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, int64, bool, error) {
+	version, dirty, err := m.Version(ctx)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		statuses = append(statuses, MigrationStatus{
+			Migration: migration,
+			Applied:   migration.Version <= version,
+		})
+	}
+	return statuses, version, dirty, nil
+}
+
+// Up applies up to steps pending migrations, or all of them when steps is 0, and returns the ones applied
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	applied := []Migration{}
+	err := m.withLock(ctx, func(c conn) error {
+		version, err := m.cleanVersion(ctx, c)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version <= version {
+				continue
+			}
+			if steps > 0 && len(applied) == steps {
+				break
+			}
+			if err := m.apply(ctx, c, migration.Version, migration.upFile, migration.Version); err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations and returns the ones reverted
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	reverted := []Migration{}
+	err := m.withLock(ctx, func(c conn) error {
+		version, err := m.cleanVersion(ctx, c)
+		if err != nil {
+			return err
+		}
+
+		plan, err := m.planDown(version, steps)
+		if err != nil {
+			return err
+		}
+		for _, step := range plan {
+			if err := m.apply(ctx, c, step.migration.Version, step.migration.downFile, step.previous); err != nil {
+				return fmt.Errorf("reverting migration %d_%s failed: %w", step.migration.Version, step.migration.Name, err)
+			}
+			reverted = append(reverted, step.migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// downStep is a migration to revert and the version recorded once it has been reverted
+type downStep struct {
+	migration Migration
+	previous  int64
+}
+
+// planDown selects the last steps migrations applied up to version, newest first. It fails before
+// anything is reverted when one of them has no .down.sql file.
+func (m *Migrator) planDown(version int64, steps int) ([]downStep, error) {
+	index := m.indexOf(version)
+	if version > 0 && index < 0 {
+		return nil, fmt.Errorf("applied version %d is not a known migration", version)
+	}
+
+	plan := []downStep{}
+	for ; index >= 0 && len(plan) < steps; index-- {
+		migration := m.migrations[index]
+		if migration.downFile == "" {
+			return nil, fmt.Errorf("migration %d_%s has no .down.sql file", migration.Version, migration.Name)
+		}
+		previous := int64(0)
+		if index > 0 {
+			previous = m.migrations[index-1].Version
+		}
+		plan = append(plan, downStep{migration: migration, previous: previous})
+	}
+	return plan, nil
+}
+
+// Force records version as applied and clean without running any SQL, to recover from a dirty schema.
+// Version 0 records that no migration has been applied.
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	if version != 0 && m.indexOf(version) < 0 {
+		return fmt.Errorf("version %d is not a known migration", version)
+	}
+	return m.withLock(ctx, func(c conn) error {
+		if err := ensureTable(ctx, c); err != nil {
+			return err
+		}
+		return setVersion(ctx, c, version, false)
+	})
+}
+
+// indexOf returns the position of version in the migration list, or -1
+func (m *Migrator) indexOf(version int64) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// cleanVersion creates the version table if needed and refuses to continue from a dirty schema
+func (m *Migrator) cleanVersion(ctx context.Context, c conn) (int64, error) {
+	if err := ensureTable(ctx, c); err != nil {
+		return 0, err
+	}
+	version, dirty, err := readVersion(ctx, c)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, &DirtyError{Version: version}
+	}
+	return version, nil
+}
+
+// apply runs one migration file. The version is marked dirty first, so a failure part way through
+// a file (MySQL cannot roll back DDL) is detected instead of silently retried.
+func (m *Migrator) apply(ctx context.Context, c conn, version int64, file string, result int64) error {
+	script, err := fs.ReadFile(m.fsys, file)
+	if err != nil {
+		return err
+	}
+
+	if err := setVersion(ctx, c, version, true); err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(script)) != "" {
+		if _, err := c.ExecContext(ctx, string(script)); err != nil {
+			return err
+		}
+	}
+	return setVersion(ctx, c, result, false)
+}
+
+// withLock runs fn on a single connection holding the migration lock
+func (m *Migrator) withLock(ctx context.Context, fn func(c conn) error) error {
+	c, err := m.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	var acquired sql.NullInt64
+	timeout := int(math.Ceil(m.LockTimeout.Seconds()))
+	if err := c.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, timeout).Scan(&acquired); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return ErrLocked
+	}
+	defer func() {
+		// Release with a fresh context so a cancelled run still frees the lock
+		c.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+	}()
+
+	return fn(c)
+}
+
+// ensureTable creates the schema_migrations table if it does not exist
+func ensureTable(ctx context.Context, c conn) error {
+	_, err := c.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL, dirty boolean NOT NULL, PRIMARY KEY (version))")
+	return err
+}
+
+// readVersion returns the recorded version, treating a missing table or row as version 0
+func readVersion(ctx context.Context, q rowQuerier) (int64, bool, error) {
+	var version int64
+	var dirty bool
+	err := q.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.Is(err, sql.ErrNoRows) || (errors.As(err, &mysqlErr) && mysqlErr.Number == errNoSuchTable) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return version, dirty, nil
+}
+
+// setVersion replaces the single row of schema_migrations
+func setVersion(ctx context.Context, c conn, version int64, dirty bool) error {
+	tx, err := c.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+		return err
+	}
+	if version > 0 {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES (?, ?)", version, dirty); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
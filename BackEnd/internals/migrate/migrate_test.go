@@ -0,0 +1,145 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// sqlFiles builds a migration file system with a statement in every named file
+func sqlFiles(names ...string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for _, name := range names {
+		fsys[name] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+	}
+	return fsys
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		fsys     fstest.MapFS
+		versions []int64
+		names    []string
+		err      string
+	}{
+		{
+			name:     "orders by version number",
+			fsys:     sqlFiles("10_ten.up.sql", "2_two.up.sql", "2_two.down.sql", "1_one.up.sql", "1_one.down.sql"),
+			versions: []int64{1, 2, 10},
+			names:    []string{"one", "two", "ten"},
+		},
+		{
+			name:     "ignores directories and other files",
+			fsys:     fstest.MapFS{"000001_init.up.sql": {}, "README.md": {}, "seeds/000002_x.up.sql": {}},
+			versions: []int64{1},
+			names:    []string{"init"},
+		},
+		{
+			name:     "empty directory",
+			fsys:     fstest.MapFS{},
+			versions: []int64{},
+		},
+		{
+			name: "mismatched names for one version",
+			fsys: sqlFiles("000001_create_admins.up.sql", "000001_create_blogs.down.sql"),
+			err:  "share version 1",
+		},
+		{
+			name: "missing up file",
+			fsys: sqlFiles("000001_init.up.sql", "000002_seed.down.sql"),
+			err:  "migration 2_seed has no .up.sql file",
+		},
+		{
+			name: "bad version prefix",
+			fsys: sqlFiles("v1_init.up.sql"),
+			err:  "must start with a positive version number",
+		},
+		{
+			name: "version zero",
+			fsys: sqlFiles("000000_init.up.sql"),
+			err:  "must start with a positive version number",
+		},
+		{
+			name: "unknown direction",
+			fsys: sqlFiles("000001_init.sql"),
+			err:  "must end in .up.sql or .down.sql",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := Load(tt.fsys)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Load() error = %v, want it to contain %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if len(migrations) != len(tt.versions) {
+				t.Fatalf("Load() returned %d migrations, want %d", len(migrations), len(tt.versions))
+			}
+			for i, migration := range migrations {
+				if migration.Version != tt.versions[i] || migration.Name != tt.names[i] {
+					t.Errorf("migration %d = %d_%s, want %d_%s", i, migration.Version, migration.Name, tt.versions[i], tt.names[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPlanDown(t *testing.T) {
+	migrator, err := New(nil, sqlFiles(
+		"1_one.up.sql", "1_one.down.sql",
+		"2_two.up.sql", "2_two.down.sql",
+		"5_five.up.sql", "5_five.down.sql",
+		"7_seven.up.sql",
+	))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		version  int64
+		steps    int
+		reverted []int64
+		previous []int64
+		err      string
+	}{
+		{name: "last migration", version: 5, steps: 1, reverted: []int64{5}, previous: []int64{2}},
+		{name: "several migrations", version: 5, steps: 2, reverted: []int64{5, 2}, previous: []int64{2, 1}},
+		{name: "more steps than applied", version: 2, steps: 10, reverted: []int64{2, 1}, previous: []int64{1, 0}},
+		{name: "nothing applied", version: 0, steps: 1, reverted: []int64{}, previous: []int64{}},
+		{name: "unknown applied version", version: 3, steps: 1, err: "applied version 3 is not a known migration"},
+		{name: "missing down file", version: 7, steps: 2, err: "7_seven has no .down.sql file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := migrator.planDown(tt.version, tt.steps)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("planDown() error = %v, want it to contain %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("planDown() error = %v", err)
+			}
+
+			if len(plan) != len(tt.reverted) {
+				t.Fatalf("planDown() returned %d steps, want %d", len(plan), len(tt.reverted))
+			}
+			for i, step := range plan {
+				if step.migration.Version != tt.reverted[i] || step.previous != tt.previous[i] {
+					t.Errorf("step %d reverts %d to %d, want %d to %d", i, step.migration.Version, step.previous, tt.reverted[i], tt.previous[i])
+				}
+			}
+		})
+	}
+}
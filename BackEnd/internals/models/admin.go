@@ -1,11 +1,51 @@
 package models
 
+import "time"
+
+// Admin roles. Owners can manage other admins.
+const (
+	RoleOwner = "owner"
+	RoleAdmin = "admin"
+)
+
 // Admin represents the admin user model
 type Admin struct {
-	ID       int    `json:"id"`
-	Username string `json:"username"`
-	Password string `json:"-"` // Password is hidden from JSON response
-	Email    string `json:"email"`
+	ID                int    `json:"id"`
+	Username          string `json:"username"`
+	Password          string `json:"-"` // Password is hidden from JSON response
+	Email             string `json:"email"`
+	Role              string `json:"role"`
+	TOTPEnabled       bool   `json:"totp_enabled"`
+	Disabled          bool   `json:"disabled"`
+	MustResetPassword bool   `json:"must_reset_password"`
+	// TokenVersion is bumped by every password change; older sessions are rejected
+	TokenVersion int `json:"-"`
+}
+
+// AdminUser is the admin representation used by user management
+type AdminUser struct {
+	ID                int        `json:"id"`
+	Username          string     `json:"username"`
+	Email             string     `json:"email"`
+	Role              string     `json:"role"`
+	DisplayName       string     `json:"display_name"`
+	TOTPEnabled       bool       `json:"totp_enabled"`
+	DisabledAt        *time.Time `json:"disabled_at"`
+	MustResetPassword bool       `json:"must_reset_password"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// AdminUserUpdate is used by owners to update another admin
+type AdminUserUpdate struct {
+	Username string `json:"username" binding:"omitempty,username"`
+	Email    string `json:"email" binding:"omitempty,email"`
+	Role     string `json:"role" binding:"omitempty,oneof=owner admin"`
+}
+
+// AdminUserListResponse is used for paginated admin lists
+type AdminUserListResponse struct {
+	Users []AdminUser    `json:"users"`
+	Meta  MetaPagination `json:"meta"`
 }
 
 // AdminLogin is used for login credentials
@@ -16,15 +56,70 @@ type AdminLogin struct {
 
 // AdminCreate is used for admin creation
 type AdminCreate struct {
-	Username string `json:"username" binding:"required"`
+	Username string `json:"username" binding:"required,username"`
 	Password string `json:"password" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
+	Role     string `json:"role" binding:"omitempty,oneof=owner admin"`
 }
 
 // AdminResponse is used for login response
 type AdminResponse struct {
-	ID       int    `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Token    string `json:"token"`
+	ID        int    `json:"id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	Token     string `json:"token"`
+	CSRFToken string `json:"csrfToken"`
+}
+
+// PasswordForgot is used to request a password reset email
+type PasswordForgot struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// PasswordReset is used to set a new password with a reset token
+type PasswordReset struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+// PasswordChange is used by a logged in admin to change the password
+type PasswordChange struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required,min=8"`
+}
+
+// MFAPendingResponse is returned by login when a TOTP code is still required
+type MFAPendingResponse struct {
+	MFARequired bool   `json:"mfaRequired"`
+	MFAToken    string `json:"mfaToken"`
+}
+
+// MFAVerify exchanges an mfa pending token and a TOTP or recovery code for a session
+type MFAVerify struct {
+	MFAToken     string `json:"mfaToken" binding:"required"`
+	Code         string `json:"code" binding:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recoveryCode" binding:"required_without=Code"`
+}
+
+// MFACode is used to confirm or disable TOTP enrolment
+type MFACode struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFAEnrollResponse is returned when a TOTP secret is generated
+type MFAEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+// MFAConfirmResponse contains the one-time recovery codes shown after enrolment
+type MFAConfirmResponse struct {
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// RecoveryCode is a hashed one-time TOTP recovery code
+type RecoveryCode struct {
+	ID       int
+	CodeHash string
 }
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// API key scopes. Browser and bearer JWT sessions implicitly hold every scope.
+const (
+	ScopeBlogsWrite   = "blogs:write"
+	ScopeProfileWrite = "profile:write"
+	ScopeAdminsWrite  = "admins:write"
+	ScopeAuditRead    = "audit:read"
+)
+
+// APIKeyScopes lists the scopes that can be granted to a personal API key
+var APIKeyScopes = []string{ScopeBlogsWrite, ScopeProfileWrite, ScopeAdminsWrite, ScopeAuditRead}
+
+// APIKey represents a personal API key of an admin
+type APIKey struct {
+	ID         int        `json:"id"`
+	AdminID    int        `json:"admin_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyCreate is used for creating a personal API key
+type APIKeyCreate struct {
+	Name          string   `json:"name" binding:"required,max=255"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,oneof=blogs:write profile:write admins:write audit:read"`
+	ExpiresInDays int      `json:"expiresInDays" binding:"omitempty,min=1,max=365"`
+}
+
+// APIKeyCreateResponse contains the plaintext key, which is only shown once
+type APIKeyCreateResponse struct {
+	Key    string `json:"key"`
+	APIKey APIKey `json:"apiKey"`
+}
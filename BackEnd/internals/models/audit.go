@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit actions
+const (
+	AuditLogin            = "auth.login"
+	AuditLoginFailed      = "auth.login_failed"
+	AuditLoginLockout     = "auth.lockout"
+	AuditPasswordReset    = "auth.password_reset"
+	AuditPasswordChange   = "auth.password_change"
+	AuditAdminCreate      = "admin.create"
+	AuditAdminUpdate      = "admin.update"
+	AuditAdminDisable     = "admin.disable"
+	AuditAdminEnable      = "admin.enable"
+	AuditAdminReset       = "admin.force_password_reset"
+	AuditAdminPasswordSet = "admin.password_set"
+	AuditAdminDelete      = "admin.delete"
+	AuditInvitationSend   = "invitation.create"
+	AuditBlogCreate       = "blog.create"
+	AuditBlogUpdate       = "blog.update"
+	AuditBlogDelete       = "blog.delete"
+)
+
+// Audit target types
+const (
+	AuditTargetAdmin      = "admin"
+	AuditTargetBlog       = "blog"
+	AuditTargetInvitation = "invitation"
+	AuditTargetLogin      = "login"
+)
+
+// AuditEvent is a single recorded administrative action
+type AuditEvent struct {
+	ID         int64           `json:"id"`
+	ActorID    *int            `json:"actor_id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Before     json.RawMessage `json:"before" swaggertype:"object"`
+	After      json.RawMessage `json:"after" swaggertype:"object"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditFilter narrows down the audit events that are listed or exported
+type AuditFilter struct {
+	ActorID    int       `form:"actor_id"`
+	Action     string    `form:"action"`
+	TargetType string    `form:"target_type"`
+	TargetID   string    `form:"target_id"`
+	From       time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// AuditListResponse is used for paginated audit events
+type AuditListResponse struct {
+	Events []AuditEvent   `json:"events"`
+	Meta   MetaPagination `json:"meta"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "mime/multipart"
+
+// Author is the public byline of an admin who writes blog posts
+type Author struct {
+	ID          int    `json:"id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio"`
+	AvatarPath  string `json:"avatar_path"`
+}
+
+// AuthorProfileUpdate is used for updating the author profile of the logged in admin.
+// Fields left out of the form stay nil, while an empty value clears the field.
+type AuthorProfileUpdate struct {
+	DisplayName *string               `form:"display_name" binding:"omitempty,max=255"`
+	Bio         *string               `form:"bio" binding:"omitempty"`
+	Avatar      *multipart.FileHeader `form:"avatar" binding:"omitempty"`
+}
+
+// AuthorPageResponse is used for the public author page
+type AuthorPageResponse struct {
+	Author Author         `json:"author"`
+	Blogs  []BlogResponse `json:"blogs"`
+	Meta   MetaPagination `json:"meta"`
+}
@@ -12,6 +12,7 @@ type Blog struct {
 	Content     string    `json:"content" binding:"required"`
 	Slug        string    `json:"slug"`
 	ImagePath   string    `json:"image_path"`
+	AuthorID    int       `json:"author_id"`
 	PublishedAt time.Time `json:"published_at"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
@@ -38,6 +39,7 @@ type BlogResponse struct {
 	Slug        string    `json:"slug"`
 	ImagePath   string    `json:"image_path"`
 	PublishedAt time.Time `json:"published_at"`
+	Author      *Author   `json:"author,omitempty"`
 }
 
 // BlogListResponse is used for paginated list responses
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// AdminInvitation is a pending invitation for a new admin
+type AdminInvitation struct {
+	ID         int        `json:"id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	InvitedBy  *int       `json:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// InvitationCreate is used by owners to invite a new admin
+type InvitationCreate struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"omitempty,oneof=owner admin"`
+}
+
+// InvitationAccept is used by the invitee to choose a username and password
+type InvitationAccept struct {
+	Token    string `json:"token" binding:"required"`
+	Username string `json:"username" binding:"required,username"`
+	Password string `json:"password" binding:"required,min=8"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// usernamePattern keeps usernames safe to use in URLs, file names, logs and CSV exports
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,64}$`)
+
+// ValidUsername reports whether username is 3 to 64 letters, digits, underscores, dots or hyphens
+func ValidUsername(username string) bool {
+	return usernamePattern.MatchString(username)
+}
+
+// RegisterValidations adds the custom binding tags used by the request models, such as "username"
+func RegisterValidations() error {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return nil
+	}
+	return engine.RegisterValidation("username", func(fl validator.FieldLevel) bool {
+		return ValidUsername(fl.Field().String())
+	})
+}
@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/redha28/blogku/internals/models"
+	"github.com/redha28/blogku/pkg"
+)
+
+// APIKeyRepository handles database operations for personal API keys
+type APIKeyRepository interface {
+	Create(ctx context.Context, adminID int, name, prefix, keyHash string, scopes []string, expiresAt *time.Time) (int64, error)
+	GetByID(ctx context.Context, adminID, id int) (models.APIKey, error)
+	GetByPrefix(ctx context.Context, prefix string) (models.APIKey, string, error)
+	ListByAdmin(ctx context.Context, adminID int) ([]models.APIKey, error)
+	Revoke(ctx context.Context, adminID, id int) error
+	TouchLastUsed(ctx context.Context, id int) error
+}
+
+// SQLAPIKeyRepository implements APIKeyRepository with MySQL
+type SQLAPIKeyRepository struct {
+	DB *sql.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *sql.DB) APIKeyRepository {
+	return &SQLAPIKeyRepository{
+		DB: db,
+	}
+}
+
+const apiKeySelect = "SELECT id, admin_id, name, prefix, scopes, expires_at, last_used_at, revoked_at, created_at, key_hash FROM api_keys"
+
+func scanAPIKey(row rowScanner) (models.APIKey, string, error) {
+	var key models.APIKey
+	var scopes, keyHash string
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+
+	err := row.Scan(&key.ID, &key.AdminID, &key.Name, &key.Prefix, &scopes, &expiresAt, &lastUsedAt, &revokedAt, &key.CreatedAt, &keyHash)
+	if err != nil {
+		return key, "", err
+	}
+
+	key.Scopes = strings.Split(scopes, ",")
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+
+	return key, keyHash, nil
+}
+
+// Create stores a new hashed API key
+func (r *SQLAPIKeyRepository) Create(ctx context.Context, adminID int, name, prefix, keyHash string, scopes []string, expiresAt *time.Time) (int64, error) {
+	ctx, span := pkg.StartSpan(ctx, "APIKeyRepository.Create")
+	defer span.End()
+
+	result, err := r.DB.ExecContext(ctx,
+		"INSERT INTO api_keys (admin_id, name, prefix, key_hash, scopes, expires_at) VALUES (?, ?, ?, ?, ?, ?)",
+		adminID,
+		name,
+		prefix,
+		keyHash,
+		strings.Join(scopes, ","),
+		expiresAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// GetByID retrieves an API key that belongs to the admin
+func (r *SQLAPIKeyRepository) GetByID(ctx context.Context, adminID, id int) (models.APIKey, error) {
+	ctx, span := pkg.StartSpan(ctx, "APIKeyRepository.GetByID")
+	defer span.End()
+
+	key, _, err := scanAPIKey(r.DB.QueryRowContext(ctx, apiKeySelect+" WHERE id = ? AND admin_id = ?", id, adminID))
+	return key, err
+}
+
+// GetByPrefix retrieves an API key and its hash by the public prefix
+func (r *SQLAPIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (models.APIKey, string, error) {
+	ctx, span := pkg.StartSpan(ctx, "APIKeyRepository.GetByPrefix")
+	defer span.End()
+
+	return scanAPIKey(r.DB.QueryRowContext(ctx, apiKeySelect+" WHERE prefix = ? LIMIT 1", prefix))
+}
+
+// ListByAdmin retrieves all API keys of an admin, newest first
+func (r *SQLAPIKeyRepository) ListByAdmin(ctx context.Context, adminID int) ([]models.APIKey, error) {
+	ctx, span := pkg.StartSpan(ctx, "APIKeyRepository.ListByAdmin")
+	defer span.End()
+
+	rows, err := r.DB.QueryContext(ctx, apiKeySelect+" WHERE admin_id = ? ORDER BY created_at DESC, id DESC", adminID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, _, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Revoke marks an API key of the admin as revoked
+func (r *SQLAPIKeyRepository) Revoke(ctx context.Context, adminID, id int) error {
+	ctx, span := pkg.StartSpan(ctx, "APIKeyRepository.Revoke")
+	defer span.End()
+
+	result, err := r.DB.ExecContext(ctx, "UPDATE api_keys SET revoked_at = NOW() WHERE id = ? AND admin_id = ? AND revoked_at IS NULL", id, adminID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// TouchLastUsed records that an API key was just used
+func (r *SQLAPIKeyRepository) TouchLastUsed(ctx context.Context, id int) error {
+	ctx, span := pkg.StartSpan(ctx, "APIKeyRepository.TouchLastUsed")
+	defer span.End()
+
+	_, err := r.DB.ExecContext(ctx, "UPDATE api_keys SET last_used_at = NOW() WHERE id = ?", id)
+	return err
+}
@@ -0,0 +1,183 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/redha28/blogku/internals/models"
+	"github.com/redha28/blogku/pkg"
+)
+
+// AuditRepository handles database operations for audit events
+type AuditRepository interface {
+	Record(ctx context.Context, event models.AuditEvent) error
+	List(ctx context.Context, filter models.AuditFilter, page, limit int) ([]models.AuditEvent, int, error)
+	Export(ctx context.Context, filter models.AuditFilter, fn func(models.AuditEvent) error) error
+}
+
+// SQLAuditRepository implements AuditRepository with MySQL
+type SQLAuditRepository struct {
+	DB *sql.DB
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &SQLAuditRepository{
+		DB: db,
+	}
+}
+
+const auditSelect = "SELECT id, actor_id, action, target_type, target_id, before_data, after_data, ip, user_agent, created_at FROM audit_events"
+
+func scanAuditEvent(row rowScanner) (models.AuditEvent, error) {
+	var event models.AuditEvent
+	var actorID sql.NullInt64
+	var targetID, ip, userAgent sql.NullString
+	var before, after []byte
+
+	err := row.Scan(&event.ID, &actorID, &event.Action, &event.TargetType, &targetID, &before, &after, &ip, &userAgent, &event.CreatedAt)
+	if err != nil {
+		return event, err
+	}
+
+	if actorID.Valid {
+		id := int(actorID.Int64)
+		event.ActorID = &id
+	}
+	event.TargetID = targetID.String
+	event.IP = ip.String
+	event.UserAgent = userAgent.String
+	if len(before) > 0 {
+		event.Before = before
+	}
+	if len(after) > 0 {
+		event.After = after
+	}
+
+	return event, nil
+}
+
+// auditWhere builds the WHERE clause for a filter
+func auditWhere(filter models.AuditFilter) (string, []any) {
+	conditions := []string{}
+	values := []any{}
+
+	if filter.ActorID != 0 {
+		conditions = append(conditions, "actor_id = ?")
+		values = append(values, filter.ActorID)
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		values = append(values, filter.Action)
+	}
+	if filter.TargetType != "" {
+		conditions = append(conditions, "target_type = ?")
+		values = append(values, filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		conditions = append(conditions, "target_id = ?")
+		values = append(values, filter.TargetID)
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		values = append(values, filter.From)
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		values = append(values, filter.To)
+	}
+
+	if len(conditions) == 0 {
+		return "", values
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), values
+}
+
+// nullableJSON stores empty JSON as NULL
+func nullableJSON(data []byte) any {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
+
+// Record stores an audit event
+func (r *SQLAuditRepository) Record(ctx context.Context, event models.AuditEvent) error {
+	ctx, span := pkg.StartSpan(ctx, "AuditRepository.Record")
+	defer span.End()
+
+	var targetID any
+	if event.TargetID != "" {
+		targetID = event.TargetID
+	}
+
+	_, err := r.DB.ExecContext(ctx,
+		"INSERT INTO audit_events (actor_id, action, target_type, target_id, before_data, after_data, ip, user_agent) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		event.ActorID,
+		event.Action,
+		event.TargetType,
+		targetID,
+		nullableJSON(event.Before),
+		nullableJSON(event.After),
+		event.IP,
+		event.UserAgent,
+	)
+	return err
+}
+
+// List retrieves audit events matching the filter, newest first, returning the total count
+func (r *SQLAuditRepository) List(ctx context.Context, filter models.AuditFilter, page, limit int) ([]models.AuditEvent, int, error) {
+	ctx, span := pkg.StartSpan(ctx, "AuditRepository.List")
+	defer span.End()
+
+	where, values := auditWhere(filter)
+
+	var total int
+	if err := r.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_events"+where, values...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, auditSelect+where+" ORDER BY id DESC LIMIT ? OFFSET ?", append(values, limit, (page-1)*limit)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	events := []models.AuditEvent{}
+	for rows.Next() {
+		event, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		events = append(events, event)
+	}
+
+	return events, total, rows.Err()
+}
+
+// Export streams every audit event matching the filter, newest first
+func (r *SQLAuditRepository) Export(ctx context.Context, filter models.AuditFilter, fn func(models.AuditEvent) error) error {
+	ctx, span := pkg.StartSpan(ctx, "AuditRepository.Export")
+	defer span.End()
+
+	where, values := auditWhere(filter)
+
+	rows, err := r.DB.QueryContext(ctx, auditSelect+where+" ORDER BY id DESC", values...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		event, err := scanAuditEvent(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -1,16 +1,45 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/redha28/blogku/internals/models"
+	"github.com/redha28/blogku/pkg"
 )
 
+// ErrLastOwner is returned when a change would leave no active owner
+var ErrLastOwner = errors.New("at least one active owner is required")
+
 // AuthRepository handles database operations for authentication
 type AuthRepository interface {
-	GetAdminForAuth(identifier string) (*models.Admin, string, error)
-	CheckIfAdminExists(username, email string) (bool, error)
-	CreateAdmin(admin models.AdminCreate, hashedPassword string) (int64, error)
+	GetAdminForAuth(ctx context.Context, identifier string) (*models.Admin, string, error)
+	CheckIfAdminExists(ctx context.Context, username, email string) (bool, error)
+	CreateAdmin(ctx context.Context, admin models.AdminCreate, hashedPassword string) (int64, error)
+	GetAdminByID(ctx context.Context, id int) (*models.Admin, error)
+	GetTOTP(ctx context.Context, adminID int) (secret string, enabled bool, err error)
+	SetPendingTOTPSecret(ctx context.Context, adminID int, secret string) error
+	EnableTOTP(ctx context.Context, adminID int, recoveryCodeHashes []string) error
+	DisableTOTP(ctx context.Context, adminID int) error
+	UseTOTPStep(ctx context.Context, adminID int, step int64) (bool, error)
+	GetUnusedRecoveryCodes(ctx context.Context, adminID int) ([]models.RecoveryCode, error)
+	UseRecoveryCode(ctx context.Context, id int) (bool, error)
+	GetAdminByEmail(ctx context.Context, email string) (*models.Admin, error)
+	GetPasswordHash(ctx context.Context, adminID int) (string, error)
+	UpdatePassword(ctx context.Context, adminID int, hashedPassword string) error
+	UpdatePasswordHash(ctx context.Context, adminID int, hashedPassword string) error
+	CreatePasswordReset(ctx context.Context, adminID int, tokenHash string, expiresAt time.Time) error
+	ResetPassword(ctx context.Context, tokenHash, hashedPassword string) (int, error)
+	ListAdmins(ctx context.Context, page, limit int) ([]models.AdminUser, int, error)
+	GetAdminUser(ctx context.Context, id int) (models.AdminUser, error)
+	UpdateAdmin(ctx context.Context, id int, update models.AdminUserUpdate) error
+	SetAdminDisabled(ctx context.Context, id int, disabled bool) error
+	SetMustResetPassword(ctx context.Context, id int, mustReset bool) error
+	DeleteAdmin(ctx context.Context, id int) error
 }
 
 // SQLAuthRepository implements AuthRepository with MySQL
@@ -25,14 +54,25 @@ func NewAuthRepository(db *sql.DB) AuthRepository {
 	}
 }
 
+// adminColumns are the columns scanned by adminDest
+const adminColumns = "id, username, email, role, totp_enabled, disabled_at IS NOT NULL, must_reset_password, token_version"
+
+// adminDest returns the scan destinations matching adminColumns
+func adminDest(admin *models.Admin) []any {
+	return []any{&admin.ID, &admin.Username, &admin.Email, &admin.Role, &admin.TOTPEnabled, &admin.Disabled, &admin.MustResetPassword, &admin.TokenVersion}
+}
+
 // GetAdminForAuth retrieves an admin by email or username for authentication
-func (r *SQLAuthRepository) GetAdminForAuth(identifier string) (*models.Admin, string, error) {
+func (r *SQLAuthRepository) GetAdminForAuth(ctx context.Context, identifier string) (*models.Admin, string, error) {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.GetAdminForAuth")
+	defer span.End()
+
 	var admin models.Admin
 	var hashedPassword string
 
 	// This query will match either email or username
-	query := "SELECT id, username, password, email FROM admins WHERE email = ? OR username = ? LIMIT 1"
-	err := r.DB.QueryRow(query, identifier, identifier).Scan(&admin.ID, &admin.Username, &hashedPassword, &admin.Email)
+	query := "SELECT " + adminColumns + ", password FROM admins WHERE email = ? OR username = ? LIMIT 1"
+	err := r.DB.QueryRowContext(ctx, query, identifier, identifier).Scan(append(adminDest(&admin), &hashedPassword)...)
 	if err != nil {
 		return nil, "", err
 	}
@@ -41,11 +81,14 @@ func (r *SQLAuthRepository) GetAdminForAuth(identifier string) (*models.Admin, s
 }
 
 // CheckIfAdminExists checks if an admin exists by username or email
-func (r *SQLAuthRepository) CheckIfAdminExists(username, email string) (bool, error) {
+func (r *SQLAuthRepository) CheckIfAdminExists(ctx context.Context, username, email string) (bool, error) {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.CheckIfAdminExists")
+	defer span.End()
+
 	var count int
 
 	query := "SELECT COUNT(*) FROM admins WHERE username = ? OR email = ?"
-	err := r.DB.QueryRow(query, username, email).Scan(&count)
+	err := r.DB.QueryRowContext(ctx, query, username, email).Scan(&count)
 	if err != nil {
 		return false, err
 	}
@@ -54,12 +97,21 @@ func (r *SQLAuthRepository) CheckIfAdminExists(username, email string) (bool, er
 }
 
 // CreateAdmin creates a new admin in the database
-func (r *SQLAuthRepository) CreateAdmin(admin models.AdminCreate, hashedPassword string) (int64, error) {
-	result, err := r.DB.Exec(
-		"INSERT INTO admins (username, password, email, created_at) VALUES (?, ?, ?, NOW())",
+func (r *SQLAuthRepository) CreateAdmin(ctx context.Context, admin models.AdminCreate, hashedPassword string) (int64, error) {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.CreateAdmin")
+	defer span.End()
+
+	role := admin.Role
+	if role == "" {
+		role = models.RoleAdmin
+	}
+
+	result, err := r.DB.ExecContext(ctx,
+		"INSERT INTO admins (username, password, email, role, created_at) VALUES (?, ?, ?, ?, NOW())",
 		admin.Username,
 		hashedPassword,
 		admin.Email,
+		role,
 	)
 	if err != nil {
 		return 0, err
@@ -72,3 +124,468 @@ func (r *SQLAuthRepository) CreateAdmin(admin models.AdminCreate, hashedPassword
 
 	return id, nil
 }
+
+// GetAdminByID retrieves an admin by ID
+func (r *SQLAuthRepository) GetAdminByID(ctx context.Context, id int) (*models.Admin, error) {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.GetAdminByID")
+	defer span.End()
+
+	var admin models.Admin
+
+	query := "SELECT " + adminColumns + " FROM admins WHERE id = ? LIMIT 1"
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(adminDest(&admin)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &admin, nil
+}
+
+// GetTOTP retrieves the TOTP secret and enrolment state of an admin
+func (r *SQLAuthRepository) GetTOTP(ctx context.Context, adminID int) (string, bool, error) {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.GetTOTP")
+	defer span.End()
+
+	var secret sql.NullString
+	var enabled bool
+
+	err := r.DB.QueryRowContext(ctx, "SELECT totp_secret, totp_enabled FROM admins WHERE id = ?", adminID).Scan(&secret, &enabled)
+	if err != nil {
+		return "", false, err
+	}
+
+	return secret.String, enabled, nil
+}
+
+// SetPendingTOTPSecret stores a TOTP secret that still has to be confirmed
+func (r *SQLAuthRepository) SetPendingTOTPSecret(ctx context.Context, adminID int, secret string) error {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.SetPendingTOTPSecret")
+	defer span.End()
+
+	_, err := r.DB.ExecContext(ctx,
+		"UPDATE admins SET totp_secret = ?, totp_enabled = 0, totp_last_step = 0 WHERE id = ? AND totp_enabled = 0",
+		secret,
+		adminID,
+	)
+	return err
+}
+
+// EnableTOTP enables TOTP and replaces the recovery codes of an admin
+func (r *SQLAuthRepository) EnableTOTP(ctx context.Context, adminID int, recoveryCodeHashes []string) error {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.EnableTOTP")
+	defer span.End()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE admins SET totp_enabled = 1 WHERE id = ?", adminID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM admin_recovery_codes WHERE admin_id = ?", adminID); err != nil {
+		return err
+	}
+	for _, hash := range recoveryCodeHashes {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO admin_recovery_codes (admin_id, code_hash) VALUES (?, ?)", adminID, hash); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DisableTOTP removes the TOTP secret and recovery codes of an admin
+func (r *SQLAuthRepository) DisableTOTP(ctx context.Context, adminID int) error {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.DisableTOTP")
+	defer span.End()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE admins SET totp_secret = NULL, totp_enabled = 0, totp_last_step = 0 WHERE id = ?", adminID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM admin_recovery_codes WHERE admin_id = ?", adminID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseTOTPStep records a used TOTP time step, returning false if it was already used
+func (r *SQLAuthRepository) UseTOTPStep(ctx context.Context, adminID int, step int64) (bool, error) {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.UseTOTPStep")
+	defer span.End()
+
+	result, err := r.DB.ExecContext(ctx, "UPDATE admins SET totp_last_step = ? WHERE id = ? AND totp_last_step < ?", step, adminID, step)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// GetUnusedRecoveryCodes retrieves the recovery codes of an admin that have not been used
+func (r *SQLAuthRepository) GetUnusedRecoveryCodes(ctx context.Context, adminID int) ([]models.RecoveryCode, error) {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.GetUnusedRecoveryCodes")
+	defer span.End()
+
+	rows, err := r.DB.QueryContext(ctx, "SELECT id, code_hash FROM admin_recovery_codes WHERE admin_id = ? AND used_at IS NULL", adminID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	codes := []models.RecoveryCode{}
+	for rows.Next() {
+		var code models.RecoveryCode
+		if err := rows.Scan(&code.ID, &code.CodeHash); err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, rows.Err()
+}
+
+// UseRecoveryCode marks a recovery code as used, returning false if it was already used
+func (r *SQLAuthRepository) UseRecoveryCode(ctx context.Context, id int) (bool, error) {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.UseRecoveryCode")
+	defer span.End()
+
+	result, err := r.DB.ExecContext(ctx, "UPDATE admin_recovery_codes SET used_at = NOW() WHERE id = ? AND used_at IS NULL", id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// GetAdminByEmail retrieves an admin by email
+func (r *SQLAuthRepository) GetAdminByEmail(ctx context.Context, email string) (*models.Admin, error) {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.GetAdminByEmail")
+	defer span.End()
+
+	var admin models.Admin
+
+	query := "SELECT " + adminColumns + " FROM admins WHERE email = ? LIMIT 1"
+	err := r.DB.QueryRowContext(ctx, query, email).Scan(adminDest(&admin)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &admin, nil
+}
+
+// GetPasswordHash retrieves the stored password hash of an admin
+func (r *SQLAuthRepository) GetPasswordHash(ctx context.Context, adminID int) (string, error) {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.GetPasswordHash")
+	defer span.End()
+
+	var hashedPassword string
+	err := r.DB.QueryRowContext(ctx, "SELECT password FROM admins WHERE id = ?", adminID).Scan(&hashedPassword)
+	return hashedPassword, err
+}
+
+// UpdatePassword replaces the password hash of an admin, clears a forced reset and invalidates its sessions
+func (r *SQLAuthRepository) UpdatePassword(ctx context.Context, adminID int, hashedPassword string) error {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.UpdatePassword")
+	defer span.End()
+
+	result, err := r.DB.ExecContext(ctx, "UPDATE admins SET password = ?, must_reset_password = 0, token_version = token_version + 1 WHERE id = ?", hashedPassword, adminID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdatePasswordHash replaces the hash of an unchanged password, such as when it is upgraded to a stronger
+// policy on login. Unlike UpdatePassword it keeps the sessions and a forced reset of the admin.
+func (r *SQLAuthRepository) UpdatePasswordHash(ctx context.Context, adminID int, hashedPassword string) error {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.UpdatePasswordHash")
+	defer span.End()
+
+	_, err := r.DB.ExecContext(ctx, "UPDATE admins SET password = ? WHERE id = ?", hashedPassword, adminID)
+	return err
+}
+
+// CreatePasswordReset stores a hashed reset token, invalidating earlier unused tokens
+func (r *SQLAuthRepository) CreatePasswordReset(ctx context.Context, adminID int, tokenHash string, expiresAt time.Time) error {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.CreatePasswordReset")
+	defer span.End()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE password_resets SET used_at = NOW() WHERE admin_id = ? AND used_at IS NULL", adminID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO password_resets (admin_id, token_hash, expires_at) VALUES (?, ?, ?)",
+		adminID,
+		tokenHash,
+		expiresAt,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ResetPassword consumes a valid reset token, sets the new password hash and invalidates the sessions of the admin.
+// It returns sql.ErrNoRows when the token is unknown, used or expired.
+func (r *SQLAuthRepository) ResetPassword(ctx context.Context, tokenHash, hashedPassword string) (int, error) {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.ResetPassword")
+	defer span.End()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var resetID, adminID int
+	err = tx.QueryRowContext(ctx,
+		"SELECT id, admin_id FROM password_resets WHERE token_hash = ? AND used_at IS NULL AND expires_at > NOW() FOR UPDATE",
+		tokenHash,
+	).Scan(&resetID, &adminID)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE admins SET password = ?, must_reset_password = 0, token_version = token_version + 1 WHERE id = ?", hashedPassword, adminID); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE password_resets SET used_at = NOW() WHERE id = ?", resetID); err != nil {
+		return 0, err
+	}
+
+	return adminID, tx.Commit()
+}
+
+const adminUserSelect = `
+		SELECT id, username, email, role, display_name, totp_enabled, disabled_at, must_reset_password, created_at
+		FROM admins`
+
+func scanAdminUser(row rowScanner) (models.AdminUser, error) {
+	var user models.AdminUser
+	var displayName sql.NullString
+	var disabledAt sql.NullTime
+
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Role, &displayName, &user.TOTPEnabled, &disabledAt, &user.MustResetPassword, &user.CreatedAt)
+	if err != nil {
+		return user, err
+	}
+	user.DisplayName = displayName.String
+	if disabledAt.Valid {
+		user.DisabledAt = &disabledAt.Time
+	}
+
+	return user, nil
+}
+
+// ListAdmins retrieves admins with pagination, returning the total count
+func (r *SQLAuthRepository) ListAdmins(ctx context.Context, page, limit int) ([]models.AdminUser, int, error) {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.ListAdmins")
+	defer span.End()
+
+	var total int
+	if err := r.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM admins").Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, adminUserSelect+" ORDER BY id LIMIT ? OFFSET ?", limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []models.AdminUser{}
+	for rows.Next() {
+		user, err := scanAdminUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+
+	return users, total, rows.Err()
+}
+
+// GetAdminUser retrieves a single admin for user management
+func (r *SQLAuthRepository) GetAdminUser(ctx context.Context, id int) (models.AdminUser, error) {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.GetAdminUser")
+	defer span.End()
+
+	return scanAdminUser(r.DB.QueryRowContext(ctx, adminUserSelect+" WHERE id = ? LIMIT 1", id))
+}
+
+// UpdateAdmin updates the provided username, email and role of an admin.
+// It returns ErrLastOwner when the role change would demote the last active owner.
+func (r *SQLAuthRepository) UpdateAdmin(ctx context.Context, id int, update models.AdminUserUpdate) error {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.UpdateAdmin")
+	defer span.End()
+
+	fields := []string{}
+	values := []any{}
+
+	if update.Username != "" {
+		fields = append(fields, "username = ?")
+		values = append(values, update.Username)
+	}
+	if update.Email != "" {
+		fields = append(fields, "email = ?")
+		values = append(values, update.Email)
+	}
+	if update.Role != "" {
+		fields = append(fields, "role = ?")
+		values = append(values, update.Role)
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	values = append(values, id)
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if update.Role != "" && update.Role != models.RoleOwner {
+		if err := keepAnotherOwner(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+
+	query := fmt.Sprintf("UPDATE admins SET %s WHERE id = ?", strings.Join(fields, ", "))
+	if _, err := tx.ExecContext(ctx, query, values...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetAdminDisabled disables or re-enables an admin account.
+// It returns ErrLastOwner when disabling the last active owner.
+func (r *SQLAuthRepository) SetAdminDisabled(ctx context.Context, id int, disabled bool) error {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.SetAdminDisabled")
+	defer span.End()
+
+	if !disabled {
+		_, err := r.DB.ExecContext(ctx, "UPDATE admins SET disabled_at = NULL WHERE id = ?", id)
+		return err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := keepAnotherOwner(ctx, tx, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE admins SET disabled_at = COALESCE(disabled_at, NOW()) WHERE id = ?", id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetMustResetPassword forces or clears a password reset on next login
+func (r *SQLAuthRepository) SetMustResetPassword(ctx context.Context, id int, mustReset bool) error {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.SetMustResetPassword")
+	defer span.End()
+
+	_, err := r.DB.ExecContext(ctx, "UPDATE admins SET must_reset_password = ? WHERE id = ?", mustReset, id)
+	return err
+}
+
+// DeleteAdmin removes an admin; their posts are kept without an author.
+// It returns ErrLastOwner when deleting the last active owner.
+func (r *SQLAuthRepository) DeleteAdmin(ctx context.Context, id int) error {
+	ctx, span := pkg.StartSpan(ctx, "AuthRepository.DeleteAdmin")
+	defer span.End()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := keepAnotherOwner(ctx, tx, id); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM admins WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return tx.Commit()
+}
+
+// keepAnotherOwner returns ErrLastOwner when the admin is the only active owner. The owner rows stay locked
+// until tx ends, so concurrent requests cannot each see two owners and remove both.
+func keepAnotherOwner(ctx context.Context, tx *sql.Tx, id int) error {
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM admins WHERE role = ? AND disabled_at IS NULL FOR UPDATE", models.RoleOwner)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	owners, isOwner := 0, false
+	for rows.Next() {
+		var ownerID int
+		if err := rows.Scan(&ownerID); err != nil {
+			return err
+		}
+		owners++
+		isOwner = isOwner || ownerID == id
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if isOwner && owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
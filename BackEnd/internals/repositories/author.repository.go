@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/redha28/blogku/internals/models"
+	"github.com/redha28/blogku/pkg"
+	"github.com/redis/go-redis/v9"
+)
+
+// AuthorRepository handles database operations for author profiles
+type AuthorRepository interface {
+	GetByID(ctx context.Context, id int) (models.Author, error)
+	GetByUsername(ctx context.Context, username string) (models.Author, error)
+	UpdateProfile(ctx context.Context, id int, profile models.AuthorProfileUpdate, avatarPath string) error
+}
+
+// SQLAuthorRepository implements AuthorRepository with MySQL
+type SQLAuthorRepository struct {
+	DB  *sql.DB
+	RDB *redis.Client
+}
+
+// NewAuthorRepository creates a new author repository
+func NewAuthorRepository(db *sql.DB, rdb *redis.Client) AuthorRepository {
+	return &SQLAuthorRepository{
+		DB:  db,
+		RDB: rdb,
+	}
+}
+
+const authorSelect = "SELECT id, username, display_name, bio, avatar_path FROM admins"
+
+func scanAuthor(row rowScanner) (models.Author, error) {
+	var author models.Author
+	var displayName, bio, avatarPath sql.NullString
+
+	if err := row.Scan(&author.ID, &author.Username, &displayName, &bio, &avatarPath); err != nil {
+		return author, err
+	}
+	author.DisplayName = displayName.String
+	author.Bio = bio.String
+	author.AvatarPath = avatarPath.String
+
+	return author, nil
+}
+
+// GetByID retrieves an author profile by admin ID
+func (r *SQLAuthorRepository) GetByID(ctx context.Context, id int) (models.Author, error) {
+	ctx, span := pkg.StartSpan(ctx, "AuthorRepository.GetByID")
+	defer span.End()
+
+	return scanAuthor(r.DB.QueryRowContext(ctx, authorSelect+" WHERE id = ? LIMIT 1", id))
+}
+
+// GetByUsername retrieves an author profile by username
+func (r *SQLAuthorRepository) GetByUsername(ctx context.Context, username string) (models.Author, error) {
+	ctx, span := pkg.StartSpan(ctx, "AuthorRepository.GetByUsername")
+	defer span.End()
+
+	return scanAuthor(r.DB.QueryRowContext(ctx, authorSelect+" WHERE username = ? LIMIT 1", username))
+}
+
+// UpdateProfile updates the provided profile fields of an author
+func (r *SQLAuthorRepository) UpdateProfile(ctx context.Context, id int, profile models.AuthorProfileUpdate, avatarPath string) error {
+	ctx, span := pkg.StartSpan(ctx, "AuthorRepository.UpdateProfile")
+	defer span.End()
+
+	fields := []string{}
+	values := []any{}
+
+	if profile.DisplayName != nil {
+		fields = append(fields, "display_name = ?")
+		values = append(values, nullableString(*profile.DisplayName))
+	}
+	if profile.Bio != nil {
+		fields = append(fields, "bio = ?")
+		values = append(values, nullableString(*profile.Bio))
+	}
+	if avatarPath != "" {
+		fields = append(fields, "avatar_path = ?")
+		values = append(values, avatarPath)
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	values = append(values, id)
+
+	query := fmt.Sprintf("UPDATE admins SET %s WHERE id = ?", strings.Join(fields, ", "))
+	result, err := r.DB.ExecContext(ctx, query, values...)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		// MySQL reports 0 rows when the values did not change, so confirm the admin exists
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	// Bylines are embedded in cached blog responses
+	r.clearBlogCaches(ctx)
+
+	return nil
+}
+
+// nullableString stores an empty profile field as NULL
+func nullableString(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// clearBlogCaches removes every cached blog list and blog post
+func (r *SQLAuthorRepository) clearBlogCaches(ctx context.Context) {
+	if _, err := clearBlogCaches(ctx, r.RDB); err != nil {
+		pkg.LoggerFromContext(ctx).Warn("Failed to clear blog caches: " + err.Error())
+	}
+}
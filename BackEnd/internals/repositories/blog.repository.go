@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log"
 	"math"
 	"mime/multipart"
 	"os"
@@ -22,29 +21,74 @@ import (
 
 // BlogRepository handles database operations for blogs
 type BlogRepository interface {
-	Create(blog models.BlogRequest, file *multipart.FileHeader) (int64, string, error)
-	GetAll(page, limit int) (models.BlogListResponse, error)
-	GetBySlug(slug string) (models.BlogResponse, error)
-	Update(id int, blog models.BlogRequestUpdate) (string, error)
-	Delete(id int) (string, error)
+	Create(ctx context.Context, blog models.BlogRequest, file *multipart.FileHeader, imageName string, authorID int) (int64, string, error)
+	GetAll(ctx context.Context, page, limit int) (models.BlogListResponse, error)
+	GetAllByAuthor(ctx context.Context, authorID, page, limit int) (models.BlogListResponse, error)
+	GetBySlug(ctx context.Context, slug string) (models.BlogResponse, error)
+	GetByID(ctx context.Context, id int) (models.BlogResponse, error)
+	Update(ctx context.Context, id int, blog models.BlogRequestUpdate) (string, error)
+	Delete(ctx context.Context, id int) (string, error)
+	ClearCache(ctx context.Context) (int, error)
 }
 
 // SQLBlogRepository implements BlogRepository with MySQL
 type SQLBlogRepository struct {
-	DB  *sql.DB
-	RDB *redis.Client
+	DB        *sql.DB
+	RDB       *redis.Client
+	UploadDir string
 }
 
-// NewBlogRepository creates a new blog repository
-func NewBlogRepository(db *sql.DB, rdb *redis.Client) BlogRepository {
+// NewBlogRepository creates a new blog repository whose images are stored in uploadDir
+func NewBlogRepository(db *sql.DB, rdb *redis.Client, uploadDir string) BlogRepository {
 	return &SQLBlogRepository{
-		DB:  db,
-		RDB: rdb,
+		DB:        db,
+		RDB:       rdb,
+		UploadDir: uploadDir,
 	}
 }
 
-// Create adds a new blog post to the database
-func (r *SQLBlogRepository) Create(blog models.BlogRequest, file *multipart.FileHeader) (int64, string, error) {
+// blogSelect selects a blog post together with its author byline
+const blogSelect = `
+		SELECT b.id, b.title, b.content, b.slug, b.image_path, b.published_at,
+			a.id, a.username, a.display_name, a.bio, a.avatar_path
+		FROM blogs b
+		LEFT JOIN admins a ON a.id = b.author_id`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanBlog scans a row selected with blogSelect into a BlogResponse
+func scanBlog(row rowScanner) (models.BlogResponse, error) {
+	var blog models.BlogResponse
+	var authorID sql.NullInt64
+	var username, displayName, bio, avatarPath sql.NullString
+
+	err := row.Scan(&blog.ID, &blog.Title, &blog.Content, &blog.Slug, &blog.ImagePath, &blog.PublishedAt,
+		&authorID, &username, &displayName, &bio, &avatarPath)
+	if err != nil {
+		return blog, err
+	}
+
+	if authorID.Valid {
+		blog.Author = &models.Author{
+			ID:          int(authorID.Int64),
+			Username:    username.String,
+			DisplayName: displayName.String,
+			Bio:         bio.String,
+			AvatarPath:  avatarPath.String,
+		}
+	}
+
+	return blog, nil
+}
+
+// Create adds a new blog post to the database. The image is stored by utils.FileHandling under imageName.
+func (r *SQLBlogRepository) Create(ctx context.Context, blog models.BlogRequest, file *multipart.FileHeader, imageName string, authorID int) (int64, string, error) {
+	ctx, span := pkg.StartSpan(ctx, "BlogRepository.Create")
+	defer span.End()
+
 	// Generate slug from title
 	ext := fp.Ext(file.Filename)
 	allowedExt := map[string]bool{
@@ -55,53 +99,55 @@ func (r *SQLBlogRepository) Create(blog models.BlogRequest, file *multipart.File
 	}
 	slug := utils.GenerateSlug(blog.Title)
 
-	pkg.Debug("Generated initial slug: " + slug)
+	pkg.LoggerFromContext(ctx).Debug("Generated initial slug: " + slug)
 
 	// Ensure slug is unique
-	uniqueSlug, err := utils.EnsureUniqueSlug(r.DB, slug, 0)
+	uniqueSlug, err := utils.EnsureUniqueSlug(ctx, r.DB, slug, 0)
 	if err != nil {
-		pkg.Error("Failed to ensure unique slug", err)
+		pkg.LoggerFromContext(ctx).Error("Failed to ensure unique slug", err)
 		return 0, "", err
 	}
 
-	pkg.Debug("Using unique slug: " + uniqueSlug)
+	pkg.LoggerFromContext(ctx).Debug("Using unique slug: " + uniqueSlug)
 
 	// Get current time
 	now := time.Now()
-	imagePath := fmt.Sprintf("%s_image%s", uniqueSlug, ext)
+	imagePath := fmt.Sprintf("%s_image%s", imageName, ext)
 	// Insert blog post
-	query := "INSERT INTO blogs (title, content, slug, image_path, published_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)"
-	result, err := r.DB.Exec(query, blog.Title, blog.Content, uniqueSlug, imagePath, now, now, now)
+	query := "INSERT INTO blogs (title, content, slug, image_path, author_id, published_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+	result, err := r.DB.ExecContext(ctx, query, blog.Title, blog.Content, uniqueSlug, imagePath, authorID, now, now, now)
 	if err != nil {
-		pkg.Error("Failed to insert blog post", err)
+		pkg.LoggerFromContext(ctx).Error("Failed to insert blog post", err)
 		return 0, "", err
 	}
 
 	id, err := result.LastInsertId()
 	if err != nil {
-		pkg.Error("Failed to get last insert ID", err)
+		pkg.LoggerFromContext(ctx).Error("Failed to get last insert ID", err)
 		return 0, "", err
 	}
 
 	// Clear cache for blog list
-	ctx := context.Background()
 	if err := r.RDB.Del(ctx, "blog:list").Err(); err != nil {
-		pkg.Warn("Failed to clear blog list cache: " + err.Error())
+		pkg.LoggerFromContext(ctx).Warn("Failed to clear blog list cache: " + err.Error())
 	} else {
-		pkg.Debug("Blog list cache cleared successfully")
+		pkg.LoggerFromContext(ctx).Debug("Blog list cache cleared successfully")
 	}
 
-	pkg.GetLogger().InfoWithFields("Blog post created", map[string]interface{}{
-		"id":   id,
-		"slug": uniqueSlug,
+	pkg.LoggerFromContext(ctx).InfoWithFields("Blog post created", map[string]interface{}{
+		"id":       id,
+		"slug":     uniqueSlug,
+		"authorId": authorID,
 	})
 
 	return id, uniqueSlug, nil
 }
 
 // GetAll retrieves all blog posts with pagination
-func (r *SQLBlogRepository) GetAll(page, limit int) (models.BlogListResponse, error) {
-	ctx := context.Background()
+func (r *SQLBlogRepository) GetAll(ctx context.Context, page, limit int) (models.BlogListResponse, error) {
+	ctx, span := pkg.StartSpan(ctx, "BlogRepository.GetAll")
+	defer span.End()
+
 	var response models.BlogListResponse
 
 	cacheKey := fmt.Sprintf("blog:list:page:%d:limit:%d", page, limit)
@@ -110,40 +156,40 @@ func (r *SQLBlogRepository) GetAll(page, limit int) (models.BlogListResponse, er
 	cachedBlogs, err := r.RDB.Get(ctx, cacheKey).Result()
 	if err == nil {
 		if err := json.Unmarshal([]byte(cachedBlogs), &response); err == nil {
-			pkg.Debug("Returning blogs from cache")
+			pkg.LoggerFromContext(ctx).Debug("Returning blogs from cache")
+			pkg.CountCacheLookup(pkg.CacheBlogList, true)
 			return response, nil
 		}
 	} else {
-		pkg.Debug("Cache miss for blog list, fetching from database")
+		pkg.LoggerFromContext(ctx).Debug("Cache miss for blog list, fetching from database")
 	}
+	pkg.CountCacheLookup(pkg.CacheBlogList, false)
 
 	offset := (page - 1) * limit
 
 	// Count total blogs
 	var total int
-	err = r.DB.QueryRow("SELECT COUNT(*) FROM blogs").Scan(&total)
+	err = r.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM blogs").Scan(&total)
 	if err != nil {
-		pkg.Error("Failed to count blogs", err)
+		pkg.LoggerFromContext(ctx).Error("Failed to count blogs", err)
 		return response, err
 	}
 
 	// Get blogs with pagination (sorted by published_at DESC)
-	rows, err := r.DB.Query(`
-		SELECT id, title, content, slug, image_path, published_at
-		FROM blogs
-		ORDER BY published_at DESC
+	rows, err := r.DB.QueryContext(ctx, blogSelect+`
+		ORDER BY b.published_at DESC
 		LIMIT ? OFFSET ?`, limit, offset)
 	if err != nil {
-		pkg.Error("Failed to query blogs", err)
+		pkg.LoggerFromContext(ctx).Error("Failed to query blogs", err)
 		return response, err
 	}
 	defer rows.Close()
 
 	blogs := []models.BlogResponse{}
 	for rows.Next() {
-		var blog models.BlogResponse
-		if err := rows.Scan(&blog.ID, &blog.Title, &blog.Content, &blog.Slug, &blog.ImagePath, &blog.PublishedAt); err != nil {
-			pkg.Error("Failed to scan blog row", err)
+		blog, err := scanBlog(rows)
+		if err != nil {
+			pkg.LoggerFromContext(ctx).Error("Failed to scan blog row", err)
 			return response, err
 		}
 		blogs = append(blogs, blog)
@@ -166,12 +212,12 @@ func (r *SQLBlogRepository) GetAll(page, limit int) (models.BlogListResponse, er
 	// Cache the result
 	cacheData, _ := json.Marshal(response)
 	if err := r.RDB.Set(ctx, cacheKey, cacheData, 10*time.Minute).Err(); err != nil {
-		pkg.Warn("Failed to cache blog list: " + err.Error())
+		pkg.LoggerFromContext(ctx).Warn("Failed to cache blog list: " + err.Error())
 	} else {
-		pkg.Debug("Blog list cached successfully")
+		pkg.LoggerFromContext(ctx).Debug("Blog list cached successfully")
 	}
 
-	pkg.GetLogger().InfoWithFields("Retrieved blog list", map[string]interface{}{
+	pkg.LoggerFromContext(ctx).InfoWithFields("Retrieved blog list", map[string]interface{}{
 		"page":       page,
 		"limit":      limit,
 		"total":      total,
@@ -181,9 +227,62 @@ func (r *SQLBlogRepository) GetAll(page, limit int) (models.BlogListResponse, er
 	return response, nil
 }
 
+// GetAllByAuthor retrieves the blog posts written by an author with pagination
+func (r *SQLBlogRepository) GetAllByAuthor(ctx context.Context, authorID, page, limit int) (models.BlogListResponse, error) {
+	ctx, span := pkg.StartSpan(ctx, "BlogRepository.GetAllByAuthor")
+	defer span.End()
+
+	var response models.BlogListResponse
+	offset := (page - 1) * limit
+
+	var total int
+	err := r.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM blogs WHERE author_id = ?", authorID).Scan(&total)
+	if err != nil {
+		pkg.LoggerFromContext(ctx).Error("Failed to count author blogs", err)
+		return response, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, blogSelect+`
+		WHERE b.author_id = ?
+		ORDER BY b.published_at DESC
+		LIMIT ? OFFSET ?`, authorID, limit, offset)
+	if err != nil {
+		pkg.LoggerFromContext(ctx).Error("Failed to query author blogs", err)
+		return response, err
+	}
+	defer rows.Close()
+
+	blogs := []models.BlogResponse{}
+	for rows.Next() {
+		blog, err := scanBlog(rows)
+		if err != nil {
+			pkg.LoggerFromContext(ctx).Error("Failed to scan blog row", err)
+			return response, err
+		}
+		blogs = append(blogs, blog)
+	}
+
+	totalPage := int(math.Ceil(float64(total) / float64(limit)))
+
+	response = models.BlogListResponse{
+		Total: total,
+		Blogs: blogs,
+		Meta: models.MetaPagination{
+			Page:       page,
+			Limit:      limit,
+			TotalPage:  totalPage,
+			TotalItems: total,
+		},
+	}
+
+	return response, nil
+}
+
 // GetBySlug retrieves a blog post by slug
-func (r *SQLBlogRepository) GetBySlug(slug string) (models.BlogResponse, error) {
-	ctx := context.Background()
+func (r *SQLBlogRepository) GetBySlug(ctx context.Context, slug string) (models.BlogResponse, error) {
+	ctx, span := pkg.StartSpan(ctx, "BlogRepository.GetBySlug")
+	defer span.End()
+
 	var blog models.BlogResponse
 
 	// Try to get from cache first
@@ -191,13 +290,14 @@ func (r *SQLBlogRepository) GetBySlug(slug string) (models.BlogResponse, error)
 	cachedBlog, err := r.RDB.Get(ctx, cacheKey).Result()
 	if err == nil {
 		if err := json.Unmarshal([]byte(cachedBlog), &blog); err == nil {
+			pkg.CountCacheLookup(pkg.CacheBlogSlug, true)
 			return blog, nil
 		}
 	}
+	pkg.CountCacheLookup(pkg.CacheBlogSlug, false)
 
 	// If not in cache, get from database
-	query := "SELECT id, title, content, slug, image_path, published_at FROM blogs WHERE slug = ? LIMIT 1"
-	err = r.DB.QueryRow(query, slug).Scan(&blog.ID, &blog.Title, &blog.Content, &blog.Slug, &blog.ImagePath, &blog.PublishedAt)
+	blog, err = scanBlog(r.DB.QueryRowContext(ctx, blogSelect+" WHERE b.slug = ? LIMIT 1", slug))
 	if err != nil {
 		return blog, err
 	}
@@ -209,11 +309,22 @@ func (r *SQLBlogRepository) GetBySlug(slug string) (models.BlogResponse, error)
 	return blog, nil
 }
 
+// GetByID retrieves a blog post by ID without using the cache
+func (r *SQLBlogRepository) GetByID(ctx context.Context, id int) (models.BlogResponse, error) {
+	ctx, span := pkg.StartSpan(ctx, "BlogRepository.GetByID")
+	defer span.End()
+
+	return scanBlog(r.DB.QueryRowContext(ctx, blogSelect+" WHERE b.id = ? LIMIT 1", id))
+}
+
 // Update modifies an existing blog post
-func (r *SQLBlogRepository) Update(id int, blog models.BlogRequestUpdate) (string, error) {
+func (r *SQLBlogRepository) Update(ctx context.Context, id int, blog models.BlogRequestUpdate) (string, error) {
+	ctx, span := pkg.StartSpan(ctx, "BlogRepository.Update")
+	defer span.End()
+
 	// Ambil slug lama untuk invalidasi cache
 	var existingSlug string
-	err := r.DB.QueryRow("SELECT slug FROM blogs WHERE id = ?", id).Scan(&existingSlug)
+	err := r.DB.QueryRowContext(ctx, "SELECT slug FROM blogs WHERE id = ?", id).Scan(&existingSlug)
 	if err != nil {
 		return "", err
 	}
@@ -226,7 +337,7 @@ func (r *SQLBlogRepository) Update(id int, blog models.BlogRequestUpdate) (strin
 	var newSlug string
 	if blog.Title != "" {
 		newSlug = utils.GenerateSlug(blog.Title)
-		uniqueSlug, err := utils.EnsureUniqueSlug(r.DB, newSlug, id)
+		uniqueSlug, err := utils.EnsureUniqueSlug(ctx, r.DB, newSlug, id)
 		if err != nil {
 			return "", err
 		}
@@ -257,13 +368,12 @@ func (r *SQLBlogRepository) Update(id int, blog models.BlogRequestUpdate) (strin
 
 	// Buat query update
 	query := fmt.Sprintf("UPDATE blogs SET %s WHERE id = ?", strings.Join(fields, ", "))
-	_, err = r.DB.Exec(query, values...)
+	_, err = r.DB.ExecContext(ctx, query, values...)
 	if err != nil {
 		return "", err
 	}
 
 	// Clear cache lama
-	ctx := context.Background()
 	r.RDB.Del(ctx, "blog:list")
 	r.RDB.Del(ctx, "blog:slug:"+existingSlug)
 
@@ -271,30 +381,54 @@ func (r *SQLBlogRepository) Update(id int, blog models.BlogRequestUpdate) (strin
 }
 
 // Delete removes a blog post
-func (r *SQLBlogRepository) Delete(id int) (string, error) {
+func (r *SQLBlogRepository) Delete(ctx context.Context, id int) (string, error) {
+	ctx, span := pkg.StartSpan(ctx, "BlogRepository.Delete")
+	defer span.End()
+
 	// Get slug before deletion for cache invalidation
 	var slug string
 	var imagePath string
-	err := r.DB.QueryRow("SELECT slug, image_path FROM blogs WHERE id = ?", id).Scan(&slug, &imagePath)
+	err := r.DB.QueryRowContext(ctx, "SELECT slug, image_path FROM blogs WHERE id = ?", id).Scan(&slug, &imagePath)
 	if err != nil {
 		return "", err
 	}
 
-	oldPath := fp.Join("public", "uploads", imagePath)
+	oldPath := fp.Join(r.UploadDir, imagePath)
 	if err := os.Remove(oldPath); err != nil && !os.IsNotExist(err) {
-		log.Println("[WARNING] Failed to delete old file:", err)
+		pkg.LoggerFromContext(ctx).Warn("Failed to delete old file: " + err.Error())
 	}
 
 	// Delete blog post
-	_, err = r.DB.Exec("DELETE FROM blogs WHERE id = ?", id)
+	_, err = r.DB.ExecContext(ctx, "DELETE FROM blogs WHERE id = ?", id)
 	if err != nil {
 		return "", err
 	}
 
 	// Clear caches
-	ctx := context.Background()
 	r.RDB.Del(ctx, "blog:list")
 	r.RDB.Del(ctx, "blog:slug:"+slug)
 
 	return slug, nil
 }
+
+// ClearCache removes every cached blog list and blog post and returns the number of keys removed
+func (r *SQLBlogRepository) ClearCache(ctx context.Context) (int, error) {
+	ctx, span := pkg.StartSpan(ctx, "BlogRepository.ClearCache")
+	defer span.End()
+
+	return clearBlogCaches(ctx, r.RDB)
+}
+
+// clearBlogCaches deletes the blog:* keys in batches with SCAN so Redis is never blocked by KEYS
+func clearBlogCaches(ctx context.Context, rdb *redis.Client) (int, error) {
+	removed := 0
+	iter := rdb.Scan(ctx, 0, "blog:*", 100).Iterator()
+	for iter.Next(ctx) {
+		deleted, err := rdb.Del(ctx, iter.Val()).Result()
+		if err != nil {
+			return removed, err
+		}
+		removed += int(deleted)
+	}
+	return removed, iter.Err()
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/redha28/blogku/pkg"
+	"github.com/redis/go-redis/v9"
+)
+
+// errNoSuchTable is the MySQL error number for a missing table
+const errNoSuchTable = 1146
+
+// ErrMigrationsNotTracked is returned when the schema_migrations table does not exist
+var ErrMigrationsNotTracked = errors.New("schema_migrations table not found")
+
+// HealthRepository checks the availability of MySQL and Redis
+type HealthRepository interface {
+	PingMySQL(ctx context.Context) error
+	PingRedis(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (version int64, dirty bool, err error)
+}
+
+// SQLHealthRepository implements HealthRepository with MySQL and Redis
+type SQLHealthRepository struct {
+	DB  *sql.DB
+	RDB *redis.Client
+}
+
+// NewHealthRepository creates a new health repository
+func NewHealthRepository(db *sql.DB, rdb *redis.Client) HealthRepository {
+	return &SQLHealthRepository{
+		DB:  db,
+		RDB: rdb,
+	}
+}
+
+// PingMySQL verifies a connection to MySQL can be used
+func (r *SQLHealthRepository) PingMySQL(ctx context.Context) error {
+	ctx, span := pkg.StartSpan(ctx, "HealthRepository.PingMySQL")
+	defer span.End()
+
+	return r.DB.PingContext(ctx)
+}
+
+// PingRedis verifies Redis answers commands
+func (r *SQLHealthRepository) PingRedis(ctx context.Context) error {
+	ctx, span := pkg.StartSpan(ctx, "HealthRepository.PingRedis")
+	defer span.End()
+
+	return r.RDB.Ping(ctx).Err()
+}
+
+// MigrationVersion returns the applied schema version recorded in schema_migrations
+func (r *SQLHealthRepository) MigrationVersion(ctx context.Context) (int64, bool, error) {
+	ctx, span := pkg.StartSpan(ctx, "HealthRepository.MigrationVersion")
+	defer span.End()
+
+	var version int64
+	var dirty bool
+	err := r.DB.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == errNoSuchTable {
+			return 0, false, ErrMigrationsNotTracked
+		}
+		if err == sql.ErrNoRows {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return version, dirty, nil
+}
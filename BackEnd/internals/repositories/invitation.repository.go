@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/redha28/blogku/internals/models"
+	"github.com/redha28/blogku/pkg"
+)
+
+// InvitationRepository handles database operations for admin invitations
+type InvitationRepository interface {
+	Create(ctx context.Context, email, role, tokenHash string, invitedBy int, expiresAt time.Time) (int64, error)
+	GetByID(ctx context.Context, id int) (models.AdminInvitation, error)
+	ListPending(ctx context.Context) ([]models.AdminInvitation, error)
+	Revoke(ctx context.Context, id int) error
+	Claim(ctx context.Context, tokenHash string) (models.AdminInvitation, error)
+	Release(ctx context.Context, id int) error
+}
+
+// SQLInvitationRepository implements InvitationRepository with MySQL
+type SQLInvitationRepository struct {
+	DB *sql.DB
+}
+
+// NewInvitationRepository creates a new invitation repository
+func NewInvitationRepository(db *sql.DB) InvitationRepository {
+	return &SQLInvitationRepository{
+		DB: db,
+	}
+}
+
+const invitationSelect = "SELECT id, email, role, invited_by, expires_at, accepted_at, revoked_at, created_at FROM admin_invitations"
+
+func scanInvitation(row rowScanner) (models.AdminInvitation, error) {
+	var invitation models.AdminInvitation
+	var invitedBy sql.NullInt64
+	var acceptedAt, revokedAt sql.NullTime
+
+	err := row.Scan(&invitation.ID, &invitation.Email, &invitation.Role, &invitedBy, &invitation.ExpiresAt, &acceptedAt, &revokedAt, &invitation.CreatedAt)
+	if err != nil {
+		return invitation, err
+	}
+
+	if invitedBy.Valid {
+		id := int(invitedBy.Int64)
+		invitation.InvitedBy = &id
+	}
+	if acceptedAt.Valid {
+		invitation.AcceptedAt = &acceptedAt.Time
+	}
+	if revokedAt.Valid {
+		invitation.RevokedAt = &revokedAt.Time
+	}
+
+	return invitation, nil
+}
+
+// Create stores a hashed invitation token, revoking earlier pending invitations for the email
+func (r *SQLInvitationRepository) Create(ctx context.Context, email, role, tokenHash string, invitedBy int, expiresAt time.Time) (int64, error) {
+	ctx, span := pkg.StartSpan(ctx, "InvitationRepository.Create")
+	defer span.End()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE admin_invitations SET revoked_at = NOW() WHERE email = ? AND accepted_at IS NULL AND revoked_at IS NULL", email); err != nil {
+		return 0, err
+	}
+	result, err := tx.ExecContext(ctx,
+		"INSERT INTO admin_invitations (email, role, token_hash, invited_by, expires_at) VALUES (?, ?, ?, ?, ?)",
+		email,
+		role,
+		tokenHash,
+		invitedBy,
+		expiresAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// GetByID retrieves an invitation
+func (r *SQLInvitationRepository) GetByID(ctx context.Context, id int) (models.AdminInvitation, error) {
+	ctx, span := pkg.StartSpan(ctx, "InvitationRepository.GetByID")
+	defer span.End()
+
+	return scanInvitation(r.DB.QueryRowContext(ctx, invitationSelect+" WHERE id = ? LIMIT 1", id))
+}
+
+// ListPending retrieves invitations that were neither accepted nor revoked, newest first
+func (r *SQLInvitationRepository) ListPending(ctx context.Context) ([]models.AdminInvitation, error) {
+	ctx, span := pkg.StartSpan(ctx, "InvitationRepository.ListPending")
+	defer span.End()
+
+	rows, err := r.DB.QueryContext(ctx, invitationSelect+" WHERE accepted_at IS NULL AND revoked_at IS NULL ORDER BY created_at DESC, id DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []models.AdminInvitation{}
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, rows.Err()
+}
+
+// Revoke cancels a pending invitation
+func (r *SQLInvitationRepository) Revoke(ctx context.Context, id int) error {
+	ctx, span := pkg.StartSpan(ctx, "InvitationRepository.Revoke")
+	defer span.End()
+
+	result, err := r.DB.ExecContext(ctx, "UPDATE admin_invitations SET revoked_at = NOW() WHERE id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Claim marks a valid invitation as accepted so its token cannot be used twice.
+// It returns sql.ErrNoRows when the token is unknown, expired, revoked or already used.
+func (r *SQLInvitationRepository) Claim(ctx context.Context, tokenHash string) (models.AdminInvitation, error) {
+	ctx, span := pkg.StartSpan(ctx, "InvitationRepository.Claim")
+	defer span.End()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.AdminInvitation{}, err
+	}
+	defer tx.Rollback()
+
+	invitation, err := scanInvitation(tx.QueryRowContext(ctx,
+		invitationSelect+" WHERE token_hash = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW() LIMIT 1 FOR UPDATE",
+		tokenHash,
+	))
+	if err != nil {
+		return invitation, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE admin_invitations SET accepted_at = NOW() WHERE id = ?", invitation.ID); err != nil {
+		return invitation, err
+	}
+
+	return invitation, tx.Commit()
+}
+
+// Release reopens a claimed invitation when creating the admin failed
+func (r *SQLInvitationRepository) Release(ctx context.Context, id int) error {
+	ctx, span := pkg.StartSpan(ctx, "InvitationRepository.Release")
+	defer span.End()
+
+	_, err := r.DB.ExecContext(ctx, "UPDATE admin_invitations SET accepted_at = NULL WHERE id = ?", id)
+	return err
+}
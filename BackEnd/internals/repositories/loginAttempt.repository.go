@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/redha28/blogku/internals/config"
+	"github.com/redha28/blogku/pkg"
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis key layout for login throttling
+const (
+	loginAttemptKeyPrefix  = "login:fail:"
+	loginLockoutKeyPrefix  = "login:lock:"
+	loginIdentifierKeyType = "id:"
+	loginIPKeyType         = "ip:"
+)
+
+// Lockout scopes
+const (
+	LockoutScopeIdentifier = "identifier"
+	LockoutScopeIP         = "ip"
+)
+
+// LoginLockout describes a lockout applied after too many failed logins
+type LoginLockout struct {
+	Scope    string
+	Key      string
+	Failures int64
+	Duration time.Duration
+}
+
+// LoginAttemptRepository tracks failed logins per identifier and per IP in Redis
+type LoginAttemptRepository interface {
+	Check(ctx context.Context, identifier, ip string) (time.Duration, error)
+	RegisterFailure(ctx context.Context, identifier, ip string) ([]LoginLockout, error)
+	Reset(ctx context.Context, identifier string) error
+}
+
+// RedisLoginAttemptRepository implements LoginAttemptRepository with Redis
+type RedisLoginAttemptRepository struct {
+	RDB    *redis.Client
+	Policy config.LoginConfig
+}
+
+// NewLoginAttemptRepository creates a new login attempt repository enforcing the given throttling policy
+func NewLoginAttemptRepository(rdb *redis.Client, policy config.LoginConfig) LoginAttemptRepository {
+	return &RedisLoginAttemptRepository{
+		RDB:    rdb,
+		Policy: policy,
+	}
+}
+
+func normalizeIdentifier(identifier string) string {
+	return strings.ToLower(strings.TrimSpace(identifier))
+}
+
+// Check returns how long the identifier or IP is still locked out, or zero
+func (r *RedisLoginAttemptRepository) Check(ctx context.Context, identifier, ip string) (time.Duration, error) {
+	ctx, span := pkg.StartSpan(ctx, "LoginAttemptRepository.Check")
+	defer span.End()
+
+	var retryAfter time.Duration
+	for _, key := range []string{
+		loginLockoutKeyPrefix + loginIdentifierKeyType + normalizeIdentifier(identifier),
+		loginLockoutKeyPrefix + loginIPKeyType + ip,
+	} {
+		ttl, err := r.RDB.PTTL(ctx, key).Result()
+		if err != nil {
+			return 0, err
+		}
+		if ttl > retryAfter {
+			retryAfter = ttl
+		}
+	}
+
+	return retryAfter, nil
+}
+
+// countFailureScript increments a failure counter and gives it the failure window as TTL in one step,
+// so a crash or Redis error can never leave a counter behind that does not expire
+var countFailureScript = redis.NewScript(`
+local failures = redis.call('INCR', KEYS[1])
+if failures == 1 or redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return failures
+`)
+
+// RegisterFailure counts a failed login and applies exponentially growing lockouts
+// once the identifier or IP exceeds its limit within the failure window
+func (r *RedisLoginAttemptRepository) RegisterFailure(ctx context.Context, identifier, ip string) ([]LoginLockout, error) {
+	ctx, span := pkg.StartSpan(ctx, "LoginAttemptRepository.RegisterFailure")
+	defer span.End()
+
+	scopes := []struct {
+		scope string
+		key   string
+		limit int64
+	}{
+		{LockoutScopeIdentifier, loginIdentifierKeyType + normalizeIdentifier(identifier), int64(r.Policy.IdentifierLimit)},
+		{LockoutScopeIP, loginIPKeyType + ip, int64(r.Policy.IPLimit)},
+	}
+
+	lockouts := []LoginLockout{}
+	for _, s := range scopes {
+		counterKey := loginAttemptKeyPrefix + s.key
+
+		failures, err := countFailureScript.Run(ctx, r.RDB, []string{counterKey}, r.Policy.FailureWindow.Milliseconds()).Int64()
+		if err != nil {
+			return lockouts, err
+		}
+		if failures < s.limit {
+			continue
+		}
+
+		duration := r.lockDuration(failures - s.limit)
+		// Keep counting for the whole lockout so the next lock is longer
+		if err := r.RDB.Expire(ctx, counterKey, duration+r.Policy.FailureWindow).Err(); err != nil {
+			return lockouts, err
+		}
+		if err := r.RDB.Set(ctx, loginLockoutKeyPrefix+s.key, failures, duration).Err(); err != nil {
+			return lockouts, err
+		}
+
+		lockouts = append(lockouts, LoginLockout{
+			Scope:    s.scope,
+			Key:      s.key,
+			Failures: failures,
+			Duration: duration,
+		})
+	}
+
+	return lockouts, nil
+}
+
+// Reset clears the failure counter and lockout of an identifier after a successful login
+func (r *RedisLoginAttemptRepository) Reset(ctx context.Context, identifier string) error {
+	ctx, span := pkg.StartSpan(ctx, "LoginAttemptRepository.Reset")
+	defer span.End()
+
+	key := loginIdentifierKeyType + normalizeIdentifier(identifier)
+	return r.RDB.Del(ctx, loginAttemptKeyPrefix+key, loginLockoutKeyPrefix+key).Err()
+}
+
+// lockDuration doubles the base lockout for every failure past the limit
+func (r *RedisLoginAttemptRepository) lockDuration(excess int64) time.Duration {
+	if excess > 16 {
+		return r.Policy.MaxLockout
+	}
+	duration := time.Duration(float64(r.Policy.BaseLockout) * math.Pow(2, float64(excess)))
+	if duration > r.Policy.MaxLockout {
+		return r.Policy.MaxLockout
+	}
+	return duration
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/redha28/blogku/pkg"
+)
+
+// UploadRepository reports which uploaded files are still referenced by the database
+type UploadRepository interface {
+	ReferencedFiles(ctx context.Context) (map[string]bool, error)
+}
+
+// SQLUploadRepository implements UploadRepository with MySQL
+type SQLUploadRepository struct {
+	DB *sql.DB
+}
+
+// NewUploadRepository creates a new upload repository
+func NewUploadRepository(db *sql.DB) UploadRepository {
+	return &SQLUploadRepository{
+		DB: db,
+	}
+}
+
+// referencedFilesQuery lists every file name stored in a column that points into the upload directory
+const referencedFilesQuery = `
+		SELECT image_path FROM blogs WHERE image_path IS NOT NULL AND image_path <> ''
+		UNION
+		SELECT avatar_path FROM admins WHERE avatar_path IS NOT NULL AND avatar_path <> ''`
+
+// ReferencedFiles returns the names of the uploaded files referenced by blog images and admin avatars
+func (r *SQLUploadRepository) ReferencedFiles(ctx context.Context) (map[string]bool, error) {
+	ctx, span := pkg.StartSpan(ctx, "UploadRepository.ReferencedFiles")
+	defer span.End()
+
+	rows, err := r.DB.QueryContext(ctx, referencedFilesQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	files := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		files[name] = true
+	}
+	return files, rows.Err()
+}
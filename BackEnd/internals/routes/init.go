@@ -2,25 +2,76 @@ package routes
 
 import (
 	"database/sql"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/internals/config"
+	"github.com/redha28/blogku/internals/handlers"
 	"github.com/redha28/blogku/internals/middlewares"
 	v1 "github.com/redha28/blogku/internals/routes/v1"
+	"github.com/redha28/blogku/pkg"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-// InitRouter initializes all routes for the application
+// InitRouter initializes all routes for the application. Work that continues after a response,
+// such as sending emails, is queued on worker.
 // @title Blog CMS API
 // @version 1.0
 // @description This is a Blog CMS API server.
 // @BasePath /
-func InitRouter(mySql *sql.DB, rdb *redis.Client) *gin.Engine {
-	router := gin.Default()
+func InitRouter(cfg *config.Config, mySql *sql.DB, rdb *redis.Client, worker *pkg.Worker) (*gin.Engine, error) {
+	// gin's own text logger and recovery are replaced by the structured ones below
+	router := gin.New()
+
+	// Without trusted proxies ClientIP is the peer address, so clients cannot pick their IP with
+	// X-Forwarded-For to dodge login lockouts and rate limits or falsify audit records
+	trustedProxies, err := pkg.ParseTrustedProxies(cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, err
+	}
+
+	// Tracing runs first so every later middleware and log line sees the request span.
+	// Probes and scrapes are not traced to keep them out of sampled traffic.
+	router.Use(otelgin.Middleware(cfg.Tracing.ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+		switch r.URL.Path {
+		case "/healthz", "/readyz", cfg.Metrics.Path:
+			return false
+		}
+		return true
+	})))
+	router.Use(middlewares.RequestIDMiddleware())
+	router.Use(middlewares.LoggerMiddleware())
+	// Metrics wrap the recovery middleware, so a panicking request is still counted as a 500
+	// and leaves the in-flight gauge
+	if cfg.Metrics.Enabled {
+		router.Use(middlewares.MetricsMiddleware())
+	}
+	router.Use(middlewares.RecoveryMiddleware())
+
+	if cfg.Metrics.Enabled {
+		pkg.RegisterDBStats(mySql, cfg.Database.Name)
+		router.GET(cfg.Metrics.Path, middlewares.MetricsAuthMiddleware(cfg.Metrics.Token), gin.WrapH(pkg.MetricsHandler()))
+	}
+
+	router.Use(middlewares.SecurityHeadersMiddleware(cfg.Security.SecurityHeadersConfig(), trustedProxies))
 
 	// Apply CORS middleware
-	router.Use(middlewares.CORSMiddleware())
+	router.Use(middlewares.CORSMiddleware(cfg.CORS.CORSConfig()))
+
+	// Uploads are served with fixed content types and without directory listings
+	staticController := handlers.NewStaticController(cfg.Uploads.Dir)
+	router.GET("/public/uploads/*filepath", staticController.ServeFile)
+	router.HEAD("/public/uploads/*filepath", staticController.ServeFile)
+	router.GET("/.well-known/jwks.json", handlers.NewJWKSController().GetJWKS)
 
-	router.Static("/public", "./public")
-	v1.InitRouter(router, mySql, rdb)
-	return router
+	// Probes for Docker and load balancers, outside /api so they are never rate limited
+	healthController := handlers.NewHealthController(mySql, rdb, cfg.Uploads.Dir)
+	router.GET("/healthz", healthController.Liveness)
+	router.GET("/readyz", healthController.Readiness)
+	v1.InitRouter(router, cfg, mySql, rdb, worker)
+	return router, nil
 }
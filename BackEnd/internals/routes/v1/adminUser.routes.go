@@ -0,0 +1,46 @@
+package v1
+
+import (
+	"database/sql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/internals/config"
+	"github.com/redha28/blogku/internals/handlers"
+	"github.com/redha28/blogku/internals/middlewares"
+	"github.com/redha28/blogku/internals/models"
+	"github.com/redha28/blogku/pkg"
+)
+
+func SetupAdminUserRoutes(router *gin.RouterGroup, cfg *config.Config, db *sql.DB, limiter *middlewares.RateLimiter, mailer pkg.Mailer) {
+	adminUserController := handlers.NewAdminUserController(db, mailer, cfg.App.URL)
+	invitationController := handlers.NewInvitationController(db, mailer, cfg.App.URL)
+
+	// Admin management is limited to owners
+	admin := router.Group("/admin")
+	admin.Use(
+		limiter.Policy(middlewares.RateLimitAdminIP),
+		middlewares.AuthMiddleware(db),
+		limiter.Policy(middlewares.RateLimitAdmin),
+		middlewares.CSRFMiddleware(),
+		middlewares.RequireScope(models.ScopeAdminsWrite),
+		middlewares.RequireRole(models.RoleOwner),
+	)
+
+	users := admin.Group("/users")
+	{
+		users.GET("", adminUserController.ListUsers)
+		users.GET("/:id", adminUserController.GetUser)
+		users.PATCH("/:id", adminUserController.UpdateUser)
+		users.DELETE("/:id", adminUserController.DeleteUser)
+		users.POST("/:id/disable", adminUserController.DisableUser)
+		users.POST("/:id/enable", adminUserController.EnableUser)
+		users.POST("/:id/force-password-reset", adminUserController.ForcePasswordReset)
+	}
+
+	invitations := admin.Group("/invitations")
+	{
+		invitations.GET("", invitationController.ListInvitations)
+		invitations.POST("", invitationController.CreateInvitation)
+		invitations.DELETE("/:id", invitationController.RevokeInvitation)
+	}
+}
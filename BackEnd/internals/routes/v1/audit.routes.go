@@ -0,0 +1,29 @@
+package v1
+
+import (
+	"database/sql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/internals/handlers"
+	"github.com/redha28/blogku/internals/middlewares"
+	"github.com/redha28/blogku/internals/models"
+)
+
+func SetupAuditRoutes(router *gin.RouterGroup, db *sql.DB, limiter *middlewares.RateLimiter) {
+	auditController := handlers.NewAuditController(db)
+
+	// The audit log is only visible to owners and their API keys with the audit:read scope
+	audit := router.Group("/admin/audit")
+	audit.Use(
+		limiter.Policy(middlewares.RateLimitAdminIP),
+		middlewares.AuthMiddleware(db),
+		limiter.Policy(middlewares.RateLimitAdmin),
+		middlewares.CSRFMiddleware(),
+		middlewares.RequireScope(models.ScopeAuditRead),
+		middlewares.RequireRole(models.RoleOwner),
+	)
+	{
+		audit.GET("", auditController.ListEvents)
+		audit.GET("/export", auditController.ExportEvents)
+	}
+}
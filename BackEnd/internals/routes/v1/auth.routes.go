@@ -2,42 +2,69 @@ package v1
 
 import (
 	"database/sql"
-	"os"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/internals/config"
 	"github.com/redha28/blogku/internals/handlers"
 	"github.com/redha28/blogku/internals/middlewares"
+	"github.com/redha28/blogku/internals/models"
+	"github.com/redha28/blogku/pkg"
+	"github.com/redis/go-redis/v9"
 )
 
-func SetupAuthRoutes(router *gin.RouterGroup, db *sql.DB) {
-	authController := handlers.NewAuthController(db)
+func SetupAuthRoutes(router *gin.RouterGroup, cfg *config.Config, db *sql.DB, rdb *redis.Client, limiter *middlewares.RateLimiter, mailer pkg.Mailer, worker *pkg.Worker) {
+	authController := handlers.NewAuthController(db, rdb, mailer, worker, cfg)
+	authorController := handlers.NewAuthorController(db, rdb, cfg.Uploads.Dir)
+	apiKeyController := handlers.NewAPIKeyController(db)
+	invitationController := handlers.NewInvitationController(db, mailer, cfg.App.URL)
 
 	auth := router.Group("/auth")
 	{
-		auth.POST("/login", authController.Login)
+		auth.POST("/login", limiter.Policy(middlewares.RateLimitAuth), authController.Login)
+		auth.POST("/login/mfa", limiter.Policy(middlewares.RateLimitAuth), authController.VerifyMFA)
+		auth.POST("/password/forgot", limiter.Policy(middlewares.RateLimitAuth), authController.ForgotPassword)
+		auth.POST("/password/reset", limiter.Policy(middlewares.RateLimitAuth), authController.ResetPassword)
+		auth.POST("/invitations/accept", limiter.Policy(middlewares.RateLimitAuth), invitationController.AcceptInvitation)
 		auth.POST("/logout", authController.Logout)
 
-		// Private admin creation route - using a special API key
-		// Should only be used internally or by a super admin
+		// Admin creation requires a logged in owner or an owner's API key with the admins:write scope
 		admin := auth.Group("/admin")
-		admin.Use(func(c *gin.Context) {
-			apiKey := c.GetHeader("X-API-Key")
-			if apiKey != os.Getenv("ADMIN_API_KEY") {
-				c.JSON(403, gin.H{"error": "Forbidden"})
-				c.Abort()
-				return
-			}
-			c.Next()
-		})
+		admin.Use(limiter.Policy(middlewares.RateLimitAdminIP), middlewares.AuthMiddleware(db), limiter.Policy(middlewares.RateLimitAdmin), middlewares.CSRFMiddleware(), middlewares.RequireScope(models.ScopeAdminsWrite), middlewares.RequireRole(models.RoleOwner))
 		{
 			admin.POST("/create", authController.CreateAdmin)
 		}
 
 		// Route that requires authentication
 		profile := auth.Group("/profile")
-		profile.Use(middlewares.AuthMiddleware())
+		profile.Use(limiter.Policy(middlewares.RateLimitAdminIP), middlewares.AuthMiddleware(db), limiter.Policy(middlewares.RateLimitAdmin), middlewares.CSRFMiddleware())
 		{
-			// Add profile routes here if needed
+			profile.GET("", authorController.GetProfile)
+			profile.PATCH("", middlewares.RequireScope(models.ScopeProfileWrite), limiter.Policy(middlewares.RateLimitUpload), authorController.UpdateProfile)
+		}
+
+		// Account security routes need a real login, not an API key
+		password := auth.Group("/password")
+		password.Use(limiter.Policy(middlewares.RateLimitAdminIP), middlewares.AuthMiddleware(db), limiter.Policy(middlewares.RateLimitAdmin), middlewares.CSRFMiddleware(), middlewares.RequireSession())
+		{
+			password.POST("/change", authController.ChangePassword)
+		}
+
+		// TOTP enrolment for the logged in admin
+		mfa := auth.Group("/mfa")
+		mfa.Use(limiter.Policy(middlewares.RateLimitAdminIP), middlewares.AuthMiddleware(db), limiter.Policy(middlewares.RateLimitAdmin), middlewares.CSRFMiddleware(), middlewares.RequireSession())
+		{
+			mfa.POST("/enroll", authController.EnrollMFA)
+			mfa.POST("/confirm", authController.ConfirmMFA)
+			mfa.POST("/disable", authController.DisableMFA)
+		}
+
+		// Personal API keys
+		apiKeys := auth.Group("/api-keys")
+		apiKeys.Use(limiter.Policy(middlewares.RateLimitAdminIP), middlewares.AuthMiddleware(db), limiter.Policy(middlewares.RateLimitAdmin), middlewares.CSRFMiddleware(), middlewares.RequireSession())
+		{
+			apiKeys.GET("", apiKeyController.ListAPIKeys)
+			apiKeys.POST("", apiKeyController.CreateAPIKey)
+			apiKeys.DELETE("/:id", apiKeyController.RevokeAPIKey)
 		}
 	}
 }
@@ -0,0 +1,18 @@
+package v1
+
+import (
+	"database/sql"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/internals/config"
+	"github.com/redha28/blogku/internals/handlers"
+	"github.com/redha28/blogku/internals/middlewares"
+	"github.com/redis/go-redis/v9"
+)
+
+func SetupAuthorRoutes(router *gin.RouterGroup, cfg *config.Config, db *sql.DB, rdb *redis.Client, limiter *middlewares.RateLimiter) {
+	authorController := handlers.NewAuthorController(db, rdb, cfg.Uploads.Dir)
+
+	// Public routes
+	router.GET("/authors/:username", limiter.Policy(middlewares.RateLimitPublic), authorController.GetAuthor)
+}
@@ -4,23 +4,25 @@ import (
 	"database/sql"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/internals/config"
 	"github.com/redha28/blogku/internals/handlers"
 	"github.com/redha28/blogku/internals/middlewares"
+	"github.com/redha28/blogku/internals/models"
 	"github.com/redis/go-redis/v9"
 )
 
-func SetupBlogRoutes(router *gin.RouterGroup, db *sql.DB, rdb *redis.Client) {
-	blogController := handlers.NewBlogController(db, rdb)
+func SetupBlogRoutes(router *gin.RouterGroup, cfg *config.Config, db *sql.DB, rdb *redis.Client, limiter *middlewares.RateLimiter) {
+	blogController := handlers.NewBlogController(db, rdb, cfg.Uploads.Dir)
 
 	// Public routes
-	router.GET("/blogs", blogController.GetAllBlogs)
-	router.GET("/blogs/:slug", blogController.GetBlogBySlug)
+	router.GET("/blogs", limiter.Policy(middlewares.RateLimitPublic), blogController.GetAllBlogs)
+	router.GET("/blogs/:slug", limiter.Policy(middlewares.RateLimitPublic), blogController.GetBlogBySlug)
 
 	// Protected routes
 	adminBlogs := router.Group("/admin/blogs")
-	adminBlogs.Use(middlewares.AuthMiddleware())
+	adminBlogs.Use(limiter.Policy(middlewares.RateLimitAdminIP), middlewares.AuthMiddleware(db), limiter.Policy(middlewares.RateLimitAdmin), middlewares.CSRFMiddleware(), middlewares.RequireScope(models.ScopeBlogsWrite))
 	{
-		adminBlogs.POST("", blogController.CreateBlog)
+		adminBlogs.POST("", limiter.Policy(middlewares.RateLimitUpload), blogController.CreateBlog)
 		adminBlogs.PATCH("/:id", blogController.UpdateBlog)
 		adminBlogs.DELETE("/:id", blogController.DeleteBlog)
 	}
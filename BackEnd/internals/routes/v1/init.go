@@ -4,13 +4,22 @@ import (
 	"database/sql"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/internals/config"
+	"github.com/redha28/blogku/internals/middlewares"
+	"github.com/redha28/blogku/pkg"
 	"github.com/redis/go-redis/v9"
 )
 
-func InitRouter(router *gin.Engine, mySql *sql.DB, rdb *redis.Client) {
+func InitRouter(router *gin.Engine, cfg *config.Config, mySql *sql.DB, rdb *redis.Client, worker *pkg.Worker) {
 	v1 := router.Group("/api/v1")
 
+	mailer := pkg.NewMailer(cfg.Mail.MailConfig())
+	limiter := middlewares.NewRateLimiter(rdb, cfg.RateLimit)
+
 	// Setup routes
-	SetupAuthRoutes(v1, mySql)
-	SetupBlogRoutes(v1, mySql, rdb)
+	SetupAuthRoutes(v1, cfg, mySql, rdb, limiter, mailer, worker)
+	SetupBlogRoutes(v1, cfg, mySql, rdb, limiter)
+	SetupAuthorRoutes(v1, cfg, mySql, rdb, limiter)
+	SetupAdminUserRoutes(v1, cfg, mySql, limiter, mailer)
+	SetupAuditRoutes(v1, mySql, limiter)
 }
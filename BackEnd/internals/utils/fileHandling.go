@@ -1,21 +1,28 @@
 package utils
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"mime/multipart"
 	"os"
 	fp "path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redha28/blogku/pkg"
 )
 
 // Utils provides utility methods
-type Utils struct{}
+type Utils struct {
+	UploadDir string
+}
 
-// NewUtils creates a new Utils instance
-func NewUtils() *Utils {
-	return &Utils{}
+// NewUtils creates a new Utils instance storing uploads in uploadDir
+func NewUtils(uploadDir string) *Utils {
+	return &Utils{
+		UploadDir: uploadDir,
+	}
 }
 
 // UploadedFile contains information about an uploaded file
@@ -26,6 +33,12 @@ type UploadedFile struct {
 	FileType string
 }
 
+// UniqueUploadName appends the current time to prefix, so every upload gets a new file name and
+// uploads can be cached as immutable
+func UniqueUploadName(prefix string) string {
+	return prefix + "-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+}
+
 // FileHandling handles file upload with validation and cleanup
 func (u *Utils) FileHandling(ctx *gin.Context, file *multipart.FileHeader, slug string, oldFilename string) (filename, filepath string, err error) {
 	ext := fp.Ext(file.Filename)
@@ -38,11 +51,13 @@ func (u *Utils) FileHandling(ctx *gin.Context, file *multipart.FileHeader, slug
 
 	// Create new filename with timestamp
 	filename = fmt.Sprintf("%s_image%s", slug, ext)
-	filepath = fp.Join("public", "uploads", filename)
+	if fp.Base(filename) != filename {
+		return "", "", fmt.Errorf("invalid file name")
+	}
+	filepath = fp.Join(u.UploadDir, filename)
 
 	// Create directory if it doesn't exist
-	uploadDir := fp.Join("public", "uploads")
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+	if err := os.MkdirAll(u.UploadDir, 0755); err != nil {
 		return "", "", fmt.Errorf("failed to create upload directory: %w", err)
 	}
 
@@ -50,14 +65,23 @@ func (u *Utils) FileHandling(ctx *gin.Context, file *multipart.FileHeader, slug
 	if err = ctx.SaveUploadedFile(file, filepath); err != nil {
 		return "", "", err
 	}
+	pkg.ObserveUpload(file.Size)
 
-	// Delete old file if exists
-	if oldFilename != "" {
-		oldPath := fp.Join("public", "uploads", oldFilename)
-		if err := os.Remove(oldPath); err != nil && !os.IsNotExist(err) {
-			log.Println("[WARNING] Failed to delete old file:", err)
-		}
+	// Delete old file if exists and was not just overwritten
+	if oldFilename != filename {
+		u.RemoveUpload(ctx.Request.Context(), oldFilename)
 	}
 
 	return filename, filepath, nil
 }
+
+// RemoveUpload deletes a file from the upload directory, logging failures. Empty names and
+// names that would leave the upload directory are ignored.
+func (u *Utils) RemoveUpload(ctx context.Context, filename string) {
+	if filename == "" || fp.Base(filename) != filename {
+		return
+	}
+	if err := os.Remove(fp.Join(u.UploadDir, filename)); err != nil && !os.IsNotExist(err) {
+		pkg.LoggerFromContext(ctx).Warn("Failed to delete old file: " + err.Error())
+	}
+}
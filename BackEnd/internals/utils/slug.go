@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"regexp"
@@ -30,7 +31,7 @@ func GenerateSlug(title string) string {
 }
 
 // EnsureUniqueSlug makes sure the slug is unique in the database
-func EnsureUniqueSlug(db *sql.DB, slug string, excludeID int) (string, error) {
+func EnsureUniqueSlug(ctx context.Context, db *sql.DB, slug string, excludeID int) (string, error) {
 	baseSlug := slug
 	counter := 1
 	uniqueSlug := slug
@@ -41,13 +42,13 @@ func EnsureUniqueSlug(db *sql.DB, slug string, excludeID int) (string, error) {
 
 		if excludeID > 0 {
 			query = "SELECT EXISTS(SELECT 1 FROM blogs WHERE slug = ? AND id != ?)"
-			err := db.QueryRow(query, uniqueSlug, excludeID).Scan(&exists)
+			err := db.QueryRowContext(ctx, query, uniqueSlug, excludeID).Scan(&exists)
 			if err != nil {
 				return "", err
 			}
 		} else {
 			query = "SELECT EXISTS(SELECT 1 FROM blogs WHERE slug = ?)"
-			err := db.QueryRow(query, uniqueSlug).Scan(&exists)
+			err := db.QueryRowContext(ctx, query, uniqueSlug).Scan(&exists)
 			if err != nil {
 				return "", err
 			}
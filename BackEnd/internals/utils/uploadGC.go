@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	fp "path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/redha28/blogku/pkg"
+)
+
+// quarantineTimeFormat names the directory each quarantine run moves its files into
+const quarantineTimeFormat = "20060102T150405"
+
+// UploadGCOptions controls how orphaned uploads are collected
+type UploadGCOptions struct {
+	// DryRun only reports what would be collected
+	DryRun bool
+	// MinAge keeps orphans younger than this, so files of requests that are still running are never touched
+	MinAge time.Duration
+	// QuarantineDir moves orphans into a timestamped directory below it instead of deleting them.
+	// It must be outside the upload directory, which is served publicly.
+	QuarantineDir string
+}
+
+// OrphanedUpload is a file in the upload directory that no row references
+type OrphanedUpload struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+	// Err is set when the file could not be deleted or moved
+	Err error
+}
+
+// UploadGCReport summarises a collection run
+type UploadGCReport struct {
+	Scanned int
+	// Orphans were old enough to collect; in a dry run none of them were touched
+	Orphans []OrphanedUpload
+	// Recent counts orphans kept because they are younger than MinAge
+	Recent int
+	// Collected counts the orphans deleted or quarantined, freeing ReclaimedBytes
+	Collected      int
+	ReclaimedBytes int64
+	// QuarantinedTo is the directory orphans were moved into, if any
+	QuarantinedTo string
+}
+
+// Failed returns the number of orphans that could not be collected
+func (r UploadGCReport) Failed() int {
+	failed := 0
+	for _, orphan := range r.Orphans {
+		if orphan.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// CollectOrphanedUploads deletes or quarantines the files in the upload directory that referenced does not
+// return. The directory is listed before referenced is called, so a file stored and referenced in between
+// is either seen as referenced or is still inside the grace period. Hidden files such as .gitkeep are kept.
+func (u *Utils) CollectOrphanedUploads(ctx context.Context, referenced func(ctx context.Context) (map[string]bool, error), opts UploadGCOptions) (UploadGCReport, error) {
+	report := UploadGCReport{Orphans: []OrphanedUpload{}}
+
+	if opts.QuarantineDir != "" && isWithin(opts.QuarantineDir, u.UploadDir) {
+		return report, fmt.Errorf("quarantine directory %s must be outside the upload directory", opts.QuarantineDir)
+	}
+
+	entries, err := os.ReadDir(u.UploadDir)
+	if err != nil {
+		return report, fmt.Errorf("failed to read upload directory: %w", err)
+	}
+
+	references, err := referenced(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to list referenced uploads: %w", err)
+	}
+
+	cutoff := time.Now().Add(-opts.MinAge)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		report.Scanned++
+		if references[name] {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			// Removed since the directory was listed
+			continue
+		}
+		if info.ModTime().After(cutoff) {
+			report.Recent++
+			continue
+		}
+		report.Orphans = append(report.Orphans, OrphanedUpload{Name: name, Size: info.Size(), ModTime: info.ModTime()})
+	}
+	sort.Slice(report.Orphans, func(i, j int) bool {
+		return report.Orphans[i].Name < report.Orphans[j].Name
+	})
+
+	if opts.DryRun || len(report.Orphans) == 0 {
+		return report, nil
+	}
+
+	if opts.QuarantineDir != "" {
+		report.QuarantinedTo = fp.Join(opts.QuarantineDir, time.Now().Format(quarantineTimeFormat))
+		if err := os.MkdirAll(report.QuarantinedTo, 0755); err != nil {
+			return report, fmt.Errorf("failed to create quarantine directory: %w", err)
+		}
+	}
+
+	logger := pkg.LoggerFromContext(ctx)
+	for i := range report.Orphans {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		orphan := &report.Orphans[i]
+		path := fp.Join(u.UploadDir, orphan.Name)
+		if report.QuarantinedTo != "" {
+			orphan.Err = moveFile(path, fp.Join(report.QuarantinedTo, orphan.Name))
+		} else {
+			orphan.Err = os.Remove(path)
+		}
+		if errors.Is(orphan.Err, os.ErrNotExist) {
+			// Collected by someone else in the meantime
+			orphan.Err = nil
+			continue
+		}
+		if orphan.Err != nil {
+			logger.ErrorWithFields("Failed to collect orphaned upload", orphan.Err, map[string]any{"file": orphan.Name})
+			continue
+		}
+
+		report.Collected++
+		report.ReclaimedBytes += orphan.Size
+	}
+
+	logger.InfoWithFields("Collected orphaned uploads", map[string]any{
+		"collected":      report.Collected,
+		"failed":         report.Failed(),
+		"reclaimedBytes": report.ReclaimedBytes,
+		"quarantinedTo":  report.QuarantinedTo,
+	})
+	return report, nil
+}
+
+// moveFile renames src to dst. When they are on different file systems, where a rename fails
+// with EXDEV, src is copied to dst, synced to disk and only then removed.
+func moveFile(src, dst string) error {
+	err := os.Rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	// Keep the modification time so the quarantined file still shows when it was uploaded
+	os.Chtimes(dst, info.ModTime(), info.ModTime())
+
+	in.Close()
+	return os.Remove(src)
+}
+
+// isWithin reports whether path is dir or a directory below it
+func isWithin(path, dir string) bool {
+	absPath, err := fp.Abs(path)
+	if err != nil {
+		return false
+	}
+	absDir, err := fp.Abs(dir)
+	if err != nil {
+		return false
+	}
+	rel, err := fp.Rel(absDir, absPath)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(fp.Separator))
+}
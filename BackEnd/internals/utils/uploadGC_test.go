@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"os"
+	fp "path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeUpload creates an upload of size bytes last modified age ago
+func writeUpload(t *testing.T, dir, name string, size int, age time.Duration) {
+	t.Helper()
+	path := fp.Join(dir, name)
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-age)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCollectOrphanedUploads(t *testing.T) {
+	referenced := func(context.Context) (map[string]bool, error) {
+		return map[string]bool{"post_image.png": true, "avatar-1-abc.png": true}, nil
+	}
+
+	tests := []struct {
+		name        string
+		quarantine  bool
+		dryRun      bool
+		orphans     []string
+		collected   int
+		reclaimed   int64
+		recent      int
+		remaining   []string
+		quarantined []string
+	}{
+		{
+			name:      "deletes old orphans",
+			orphans:   []string{"old-a.png", "old-b.png"},
+			collected: 2,
+			reclaimed: 30,
+			recent:    1,
+			remaining: []string{".gitkeep", "avatar-1-abc.png", "new.png", "post_image.png"},
+		},
+		{
+			name:      "dry run changes nothing",
+			dryRun:    true,
+			orphans:   []string{"old-a.png", "old-b.png"},
+			recent:    1,
+			remaining: []string{".gitkeep", "avatar-1-abc.png", "new.png", "old-a.png", "old-b.png", "post_image.png"},
+		},
+		{
+			name:        "quarantines old orphans",
+			quarantine:  true,
+			orphans:     []string{"old-a.png", "old-b.png"},
+			collected:   2,
+			reclaimed:   30,
+			recent:      1,
+			remaining:   []string{".gitkeep", "avatar-1-abc.png", "new.png", "post_image.png"},
+			quarantined: []string{"old-a.png", "old-b.png"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uploadDir := t.TempDir()
+			writeUpload(t, uploadDir, ".gitkeep", 0, 48*time.Hour)
+			writeUpload(t, uploadDir, "post_image.png", 100, 48*time.Hour)
+			writeUpload(t, uploadDir, "avatar-1-abc.png", 100, 48*time.Hour)
+			writeUpload(t, uploadDir, "old-a.png", 10, 48*time.Hour)
+			writeUpload(t, uploadDir, "old-b.png", 20, 25*time.Hour)
+			writeUpload(t, uploadDir, "new.png", 40, time.Hour)
+			if err := os.Mkdir(fp.Join(uploadDir, "nested"), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			opts := UploadGCOptions{DryRun: tt.dryRun, MinAge: 24 * time.Hour}
+			if tt.quarantine {
+				opts.QuarantineDir = t.TempDir()
+			}
+
+			report, err := NewUtils(uploadDir).CollectOrphanedUploads(context.Background(), referenced, opts)
+			if err != nil {
+				t.Fatalf("CollectOrphanedUploads() error = %v", err)
+			}
+
+			var orphans []string
+			for _, orphan := range report.Orphans {
+				orphans = append(orphans, orphan.Name)
+				if orphan.Err != nil {
+					t.Errorf("orphan %s failed: %v", orphan.Name, orphan.Err)
+				}
+			}
+			if !reflect.DeepEqual(orphans, tt.orphans) {
+				t.Errorf("orphans = %v, want %v", orphans, tt.orphans)
+			}
+			if report.Scanned != 5 || report.Recent != tt.recent {
+				t.Errorf("scanned %d with %d recent, want 5 with %d recent", report.Scanned, report.Recent, tt.recent)
+			}
+			if report.Collected != tt.collected || report.ReclaimedBytes != tt.reclaimed {
+				t.Errorf("collected %d reclaiming %d bytes, want %d reclaiming %d", report.Collected, report.ReclaimedBytes, tt.collected, tt.reclaimed)
+			}
+			if got := listFiles(t, uploadDir); !reflect.DeepEqual(got, tt.remaining) {
+				t.Errorf("upload directory holds %v, want %v", got, tt.remaining)
+			}
+
+			if tt.quarantined == nil {
+				if report.QuarantinedTo != "" {
+					t.Errorf("QuarantinedTo = %q, want none", report.QuarantinedTo)
+				}
+				return
+			}
+			if !strings.HasPrefix(report.QuarantinedTo, opts.QuarantineDir) {
+				t.Fatalf("QuarantinedTo = %q, want a directory below %s", report.QuarantinedTo, opts.QuarantineDir)
+			}
+			if got := listFiles(t, report.QuarantinedTo); !reflect.DeepEqual(got, tt.quarantined) {
+				t.Errorf("quarantine holds %v, want %v", got, tt.quarantined)
+			}
+		})
+	}
+}
+
+func TestCollectOrphanedUploadsErrors(t *testing.T) {
+	uploadDir := t.TempDir()
+	writeUpload(t, uploadDir, "old.png", 10, 48*time.Hour)
+	referenced := func(context.Context) (map[string]bool, error) { return map[string]bool{}, nil }
+
+	tests := []struct {
+		name       string
+		referenced func(context.Context) (map[string]bool, error)
+		opts       UploadGCOptions
+		err        string
+	}{
+		{
+			name:       "quarantine inside the upload directory",
+			referenced: referenced,
+			opts:       UploadGCOptions{QuarantineDir: fp.Join(uploadDir, "quarantine")},
+			err:        "must be outside the upload directory",
+		},
+		{
+			name:       "quarantine is the upload directory",
+			referenced: referenced,
+			opts:       UploadGCOptions{QuarantineDir: uploadDir},
+			err:        "must be outside the upload directory",
+		},
+		{
+			name:       "references cannot be listed",
+			referenced: func(context.Context) (map[string]bool, error) { return nil, errors.New("database is down") },
+			err:        "failed to list referenced uploads: database is down",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewUtils(uploadDir).CollectOrphanedUploads(context.Background(), tt.referenced, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("CollectOrphanedUploads() error = %v, want it to contain %q", err, tt.err)
+			}
+			if got := listFiles(t, uploadDir); !reflect.DeepEqual(got, []string{"old.png"}) {
+				t.Errorf("upload directory holds %v after a failed run", got)
+			}
+		})
+	}
+}
+
+// listFiles returns the sorted names of the regular files in dir
+func listFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
@@ -0,0 +1,11 @@
+-- Remove author reference from blogs
+ALTER TABLE `blogs`
+  DROP FOREIGN KEY `fk_blogs_author`,
+  DROP KEY `author_id`,
+  DROP COLUMN `author_id`;
+
+-- Remove author profile columns from admins
+ALTER TABLE `admins`
+  DROP COLUMN `avatar_path`,
+  DROP COLUMN `bio`,
+  DROP COLUMN `display_name`;
@@ -0,0 +1,14 @@
+-- Add author profile columns to admins
+ALTER TABLE `admins`
+  ADD COLUMN `display_name` varchar(255) DEFAULT NULL AFTER `email`,
+  ADD COLUMN `bio` text AFTER `display_name`,
+  ADD COLUMN `avatar_path` varchar(255) DEFAULT NULL AFTER `bio`;
+
+-- Add author reference to blogs
+ALTER TABLE `blogs`
+  ADD COLUMN `author_id` int DEFAULT NULL AFTER `image_path`,
+  ADD KEY `author_id` (`author_id`),
+  ADD CONSTRAINT `fk_blogs_author` FOREIGN KEY (`author_id`) REFERENCES `admins` (`id`) ON DELETE SET NULL;
+
+-- Attribute existing posts to the seeded admin
+UPDATE `blogs` SET `author_id` = (SELECT `id` FROM `admins` WHERE `username` = 'admin') WHERE `author_id` IS NULL;
@@ -0,0 +1,8 @@
+-- Drop recovery codes table
+DROP TABLE IF EXISTS `admin_recovery_codes`;
+
+-- Remove TOTP columns from admins
+ALTER TABLE `admins`
+  DROP COLUMN `totp_last_step`,
+  DROP COLUMN `totp_enabled`,
+  DROP COLUMN `totp_secret`;
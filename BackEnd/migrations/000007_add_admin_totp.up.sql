@@ -0,0 +1,17 @@
+-- Add TOTP two-factor columns to admins
+ALTER TABLE `admins`
+  ADD COLUMN `totp_secret` varchar(64) DEFAULT NULL AFTER `avatar_path`,
+  ADD COLUMN `totp_enabled` tinyint(1) NOT NULL DEFAULT 0 AFTER `totp_secret`,
+  ADD COLUMN `totp_last_step` bigint NOT NULL DEFAULT 0 AFTER `totp_enabled`;
+
+-- Create one-time recovery codes table
+CREATE TABLE IF NOT EXISTS `admin_recovery_codes` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `admin_id` int NOT NULL,
+  `code_hash` text NOT NULL,
+  `used_at` timestamp NULL DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `admin_id` (`admin_id`),
+  CONSTRAINT `fk_recovery_codes_admin` FOREIGN KEY (`admin_id`) REFERENCES `admins` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,2 @@
+-- Drop personal API keys table
+DROP TABLE IF EXISTS `api_keys`;
@@ -0,0 +1,5 @@
+-- Remove role and account state columns from admins
+ALTER TABLE `admins`
+  DROP COLUMN `must_reset_password`,
+  DROP COLUMN `disabled_at`,
+  DROP COLUMN `role`;
@@ -0,0 +1,8 @@
+-- Add role and account state columns to admins
+ALTER TABLE `admins`
+  ADD COLUMN `role` varchar(32) NOT NULL DEFAULT 'admin' AFTER `email`,
+  ADD COLUMN `disabled_at` timestamp NULL DEFAULT NULL AFTER `totp_last_step`,
+  ADD COLUMN `must_reset_password` tinyint(1) NOT NULL DEFAULT 0 AFTER `disabled_at`;
+
+-- The first admin becomes the owner
+UPDATE `admins` SET `role` = 'owner' ORDER BY `id` LIMIT 1;
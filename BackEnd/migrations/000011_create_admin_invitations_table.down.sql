@@ -0,0 +1,2 @@
+-- Drop admin invitations table
+DROP TABLE IF EXISTS `admin_invitations`;
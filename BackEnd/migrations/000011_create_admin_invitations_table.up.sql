@@ -0,0 +1,16 @@
+-- Create admin invitations table
+CREATE TABLE IF NOT EXISTS `admin_invitations` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `email` varchar(255) NOT NULL,
+  `role` varchar(32) NOT NULL DEFAULT 'admin',
+  `token_hash` char(64) NOT NULL,
+  `invited_by` int DEFAULT NULL,
+  `expires_at` timestamp NOT NULL,
+  `accepted_at` timestamp NULL DEFAULT NULL,
+  `revoked_at` timestamp NULL DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `token_hash` (`token_hash`),
+  KEY `email` (`email`),
+  CONSTRAINT `fk_admin_invitations_inviter` FOREIGN KEY (`invited_by`) REFERENCES `admins` (`id`) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
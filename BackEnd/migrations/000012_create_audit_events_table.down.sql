@@ -0,0 +1,2 @@
+-- Drop audit events table
+DROP TABLE IF EXISTS `audit_events`;
@@ -0,0 +1,18 @@
+-- Create audit events table. actor_id has no foreign key so history survives deleted admins.
+CREATE TABLE IF NOT EXISTS `audit_events` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `actor_id` int DEFAULT NULL,
+  `action` varchar(64) NOT NULL,
+  `target_type` varchar(64) NOT NULL,
+  `target_id` varchar(64) DEFAULT NULL,
+  `before_data` json DEFAULT NULL,
+  `after_data` json DEFAULT NULL,
+  `ip` varchar(45) DEFAULT NULL,
+  `user_agent` varchar(512) DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `created_at` (`created_at`),
+  KEY `actor_id` (`actor_id`),
+  KEY `action` (`action`),
+  KEY `target` (`target_type`, `target_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,3 @@
+-- Remove the session version column from admins
+ALTER TABLE `admins`
+  DROP COLUMN `token_version`;
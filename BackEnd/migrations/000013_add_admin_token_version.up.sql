@@ -0,0 +1,3 @@
+-- Count password changes so sessions issued before the latest one can be rejected
+ALTER TABLE `admins`
+  ADD COLUMN `token_version` int NOT NULL DEFAULT 0 AFTER `must_reset_password`;
@@ -0,0 +1,9 @@
+// Package migrations embeds the versioned SQL migrations so the binary can apply them itself
+package migrations
+
+import "embed"
+
+// FS holds every <version>_<name>.up.sql and .down.sql file in this directory
+//
+//go:embed *.sql
+var FS embed.FS
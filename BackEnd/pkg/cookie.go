@@ -0,0 +1,28 @@
+package pkg
+
+import (
+	"net/http"
+	"strings"
+)
+
+// CookieConfig holds the attributes applied to every cookie the API sets
+type CookieConfig struct {
+	Secure   bool
+	SameSite http.SameSite
+	Domain   string
+	Path     string
+}
+
+// ParseSameSite converts lax, strict or none into an http.SameSite mode
+func ParseSameSite(value string) (http.SameSite, bool) {
+	switch strings.ToLower(value) {
+	case "lax":
+		return http.SameSiteLaxMode, true
+	case "strict":
+		return http.SameSiteStrictMode, true
+	case "none":
+		return http.SameSiteNoneMode, true
+	default:
+		return http.SameSiteDefaultMode, false
+	}
+}
@@ -0,0 +1,56 @@
+package pkg
+
+import (
+	"strings"
+)
+
+// CORSConfig describes which browser origins may call the API
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	MaxAge           int
+	AllowCredentials bool
+}
+
+// AllowOrigin reports whether the origin is allowed and the value for Access-Control-Allow-Origin.
+// Patterns like https://*.example.com match any subdomain of example.com over https, but not example.com itself.
+func (cfg CORSConfig) AllowOrigin(origin string) (string, bool) {
+	if origin == "" {
+		return "", false
+	}
+	origin = strings.ToLower(origin)
+
+	for _, allowed := range cfg.AllowedOrigins {
+		allowed = strings.ToLower(strings.TrimRight(allowed, "/"))
+		switch {
+		case allowed == "*":
+			return "*", true
+		case allowed == origin:
+			return origin, true
+		case strings.Contains(allowed, "://*."):
+			if matchWildcardOrigin(allowed, origin) {
+				return origin, true
+			}
+		}
+	}
+	return "", false
+}
+
+// matchWildcardOrigin matches scheme://*.domain[:port] against an origin
+func matchWildcardOrigin(pattern, origin string) bool {
+	star := strings.Index(pattern, "*")
+	prefix, suffix := pattern[:star], pattern[star+1:]
+	if !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) || len(origin) <= len(prefix)+len(suffix) {
+		return false
+	}
+
+	subdomain := origin[len(prefix) : len(origin)-len(suffix)]
+	for _, r := range subdomain {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '.') {
+			return false
+		}
+	}
+	return !strings.HasPrefix(subdomain, ".") && !strings.HasSuffix(subdomain, ".")
+}
@@ -0,0 +1,43 @@
+package pkg
+
+import "testing"
+
+func TestCORSConfigAllowOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		want    string
+		ok      bool
+	}{
+		{name: "exact origin", allowed: []string{"https://blog.com"}, origin: "https://blog.com", want: "https://blog.com", ok: true},
+		{name: "trailing slash in the setting", allowed: []string{"https://blog.com/"}, origin: "https://blog.com", want: "https://blog.com", ok: true},
+		{name: "case-insensitive", allowed: []string{"https://Blog.com"}, origin: "HTTPS://BLOG.COM", want: "https://blog.com", ok: true},
+		{name: "other origin", allowed: []string{"https://blog.com"}, origin: "https://evil.com", ok: false},
+		{name: "other scheme", allowed: []string{"https://blog.com"}, origin: "http://blog.com", ok: false},
+		{name: "other port", allowed: []string{"https://blog.com"}, origin: "https://blog.com:8443", ok: false},
+		{name: "empty origin", allowed: []string{"*"}, origin: "", ok: false},
+		{name: "any origin", allowed: []string{"*"}, origin: "https://evil.com", want: "*", ok: true},
+		{name: "wildcard subdomain", allowed: []string{"https://*.blog.com"}, origin: "https://admin.blog.com", want: "https://admin.blog.com", ok: true},
+		{name: "wildcard nested subdomain", allowed: []string{"https://*.blog.com"}, origin: "https://a.b.blog.com", want: "https://a.b.blog.com", ok: true},
+		{name: "wildcard with port", allowed: []string{"http://*.blog.local:3000"}, origin: "http://app.blog.local:3000", want: "http://app.blog.local:3000", ok: true},
+		{name: "wildcard without subdomain", allowed: []string{"https://*.blog.com"}, origin: "https://blog.com", ok: false},
+		{name: "wildcard with empty label", allowed: []string{"https://*.blog.com"}, origin: "https://.blog.com", ok: false},
+		{name: "wildcard suffix attack", allowed: []string{"https://*.blog.com"}, origin: "https://evilblog.com", ok: false},
+		{name: "wildcard in another domain", allowed: []string{"https://*.blog.com"}, origin: "https://blog.com.evil.com", ok: false},
+		{name: "wildcard with path characters", allowed: []string{"https://*.blog.com"}, origin: "https://evil.com/x.blog.com", ok: false},
+		{name: "wildcard other scheme", allowed: []string{"https://*.blog.com"}, origin: "http://admin.blog.com", ok: false},
+		{name: "wildcard other port", allowed: []string{"https://*.blog.com"}, origin: "https://admin.blog.com:8443", ok: false},
+		{name: "second entry matches", allowed: []string{"https://blog.com", "https://*.blog.dev"}, origin: "https://preview.blog.dev", want: "https://preview.blog.dev", ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := CORSConfig{AllowedOrigins: tt.allowed}
+			got, ok := cfg.AllowOrigin(tt.origin)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("AllowOrigin(%q) = %q, %v, want %q, %v", tt.origin, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
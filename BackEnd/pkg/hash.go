@@ -38,6 +38,23 @@ func (h *HashConfig) UseDefaultConfig() {
 	h.SaltLen = 16
 }
 
+// hashPolicy is the process wide policy applied by UsePolicyConfig
+var hashPolicy *HashConfig
+
+// SetHashPolicy sets the policy used for new hashes and rehash checks
+func SetHashPolicy(policy HashConfig) {
+	hashPolicy = &policy
+}
+
+// UsePolicyConfig applies the policy set with SetHashPolicy, or the default policy when none is set
+func (h *HashConfig) UsePolicyConfig() {
+	if hashPolicy == nil {
+		h.UseDefaultConfig()
+		return
+	}
+	*h = *hashPolicy
+}
+
 func (h *HashConfig) genSalt() ([]byte, error) {
 	salt := make([]byte, h.SaltLen)
 	if _, err := rand.Read(salt); err != nil {
@@ -60,44 +77,64 @@ func (h *HashConfig) GenHashedPassword(password string) (string, error) {
 	return hashedPwd, nil
 }
 
+// CompareHashAndPassword checks a password using the parameters stored in the hash.
+// The receiver keeps its own policy so NeedsRehash can be called afterwards.
 func (h *HashConfig) CompareHashAndPassword(hadhedPass string, password string) (bool, error) {
-	salt, hash, err := h.decodeHash(hadhedPass)
+	params, salt, hash, err := decodeHash(hadhedPass)
 	if err != nil {
 		return false, err
 	}
-	newHash := argon2.IDKey([]byte(password), salt, h.Time, h.Memory, h.Threads, h.KeyLen)
+	newHash := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, uint32(len(hash)))
 	if subtle.ConstantTimeCompare(hash, newHash) == 0 {
 		return false, err
 	}
 	return true, nil
 }
 
-func (h *HashConfig) decodeHash(hashedPass string) (salt []byte, hash []byte, err error) {
+// NeedsRehash reports whether a stored hash is weaker than the current policy
+func (h *HashConfig) NeedsRehash(hashedPass string) bool {
+	params, salt, hash, err := decodeHash(hashedPass)
+	if err != nil {
+		return false
+	}
+	return params.Time < h.Time ||
+		params.Memory < h.Memory ||
+		params.Threads < h.Threads ||
+		uint32(len(hash)) < h.KeyLen ||
+		uint32(len(salt)) < h.SaltLen
+}
+
+func decodeHash(hashedPass string) (params HashConfig, salt []byte, hash []byte, err error) {
 	// $jenisKey$versiKey$konfigurasi(memory, time, thread)$salt$hash
 	values := strings.Split(hashedPass, "$")
 	if len(values) != 6 {
-		return nil, nil, fmt.Errorf("invalid length format")
+		return params, nil, nil, fmt.Errorf("invalid length format")
 	}
 	if values[1] != "argon2id" {
-		return nil, nil, fmt.Errorf("invalid hash type")
+		return params, nil, nil, fmt.Errorf("invalid hash type")
 	}
 	var version int
 	if _, err := fmt.Sscanf(values[2], "v=%d", &version); err != nil {
-		return nil, nil, err
+		return params, nil, nil, err
 	}
 	if version != argon2.Version {
-		return nil, nil, fmt.Errorf("invalid hash version")
+		return params, nil, nil, fmt.Errorf("invalid hash version")
 	}
-	if _, err := fmt.Sscanf(values[3], "m=%d,t=%d,p=%d", &h.Memory, &h.Time, &h.Threads); err != nil {
-		return nil, nil, err
+	if _, err := fmt.Sscanf(values[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return params, nil, nil, err
 	}
 	salt, err = base64.RawStdEncoding.DecodeString(values[4])
 	if err != nil {
-		return nil, nil, err
+		return params, nil, nil, err
 	}
 	hash, err = base64.RawStdEncoding.DecodeString(values[5])
 	if err != nil {
-		return nil, nil, err
+		return params, nil, nil, err
+	}
+	if len(hash) == 0 {
+		return params, nil, nil, fmt.Errorf("invalid hash length")
 	}
-	return salt, hash, nil
+	params.KeyLen = uint32(len(hash))
+	params.SaltLen = uint32(len(salt))
+	return params, salt, hash, nil
 }
@@ -2,18 +2,32 @@ package pkg
 
 import (
 	"errors"
-	"os"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// RoleMFAPending marks a short-lived token that only allows completing a TOTP login
+const RoleMFAPending = "mfa_pending"
+
 type Payload struct {
 	Id   string `json:"id"`
 	Role string `json:"role"`
+	CSRF string `json:"csrf,omitempty"`
+	// Version is the token version of the admin when the token was issued
+	Version int `json:"ver,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// jwtIssuer is the iss claim of issued tokens
+var jwtIssuer string
+
+// SetJWTIssuer sets the iss claim of tokens issued from now on
+func SetJWTIssuer(issuer string) {
+	jwtIssuer = issuer
+}
+
 type JWTErr struct {
 	Type string
 	Err  error
@@ -24,32 +38,49 @@ func NewPayload(id, role string) *Payload {
 		Id:   id,
 		Role: role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    os.Getenv("JWT_ISSUER"),
+			Issuer:    jwtIssuer,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24)),
 		},
 	}
 }
 
+// GenerateToken signs the payload with the active key of the key set
 func (c *Payload) GenerateToken() (string, error) {
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		return "", errors.New("Secret not provided")
+	ks, err := GetKeySet()
+	if err != nil {
+		return "", err
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, c)
-	return token.SignedString([]byte(jwtSecret))
+	key := ks.Active()
+	signingKey, err := key.signingKey()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.method(), c)
+	token.Header["kid"] = key.ID
+	return token.SignedString(signingKey)
 }
 
+// VerifyToken validates the token with the key named by its kid header,
+// accepting both the active key and retired keys
 func (c *Payload) VerifyToken(token string) JWTErr {
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
+	ks, err := GetKeySet()
+	if err != nil {
 		return JWTErr{
 			Type: "System",
-			Err:  errors.New("Secret not provided"),
+			Err:  err,
 		}
 	}
 	parsedToken, err := jwt.ParseWithClaims(token, c, func(t *jwt.Token) (any, error) {
-		return []byte(jwtSecret), nil
-	})
+		kid, _ := t.Header["kid"].(string)
+		key, ok := ks.Lookup(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown key %q", kid)
+		}
+		if t.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+		}
+		return key.verificationKey(), nil
+	}, jwt.WithValidMethods(ks.Algorithms()))
 	if err != nil {
 		return JWTErr{
 			Type: "Token",
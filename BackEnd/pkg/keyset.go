@@ -0,0 +1,329 @@
+package pkg
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported JWT signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// legacyKeyID is used for JWT_SECRET and for tokens issued before kid headers existed
+const legacyKeyID = "default"
+
+// SigningKey is a single JWT key identified by its kid
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	Retired   bool
+
+	secret  []byte
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// method returns the jwt signing method of the key
+func (k *SigningKey) method() jwt.SigningMethod {
+	switch k.Algorithm {
+	case AlgRS256:
+		return jwt.SigningMethodRS256
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// signingKey returns the key material used to sign tokens
+func (k *SigningKey) signingKey() (any, error) {
+	if k.Algorithm == AlgHS256 {
+		return k.secret, nil
+	}
+	if k.private == nil {
+		return nil, fmt.Errorf("key %q has no private key", k.ID)
+	}
+	return k.private, nil
+}
+
+// verificationKey returns the key material used to verify tokens
+func (k *SigningKey) verificationKey() any {
+	if k.Algorithm == AlgHS256 {
+		return k.secret
+	}
+	return k.public
+}
+
+// KeySet holds the active signing key plus retired keys that are still accepted for verification
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// keySetFile is the JSON layout of JWT_KEYS_FILE
+type keySetFile struct {
+	Active string `json:"active"`
+	Keys   []struct {
+		ID             string `json:"kid"`
+		Algorithm      string `json:"alg"`
+		Secret         string `json:"secret"`
+		SecretEnv      string `json:"secret_env"`
+		PrivateKeyFile string `json:"private_key_file"`
+		PublicKeyFile  string `json:"public_key_file"`
+		Retired        bool   `json:"retired"`
+	} `json:"keys"`
+}
+
+var (
+	keySet   *KeySet
+	keySetMu sync.RWMutex
+)
+
+// GetKeySet returns the process wide key set installed with SetKeySet
+func GetKeySet() (*KeySet, error) {
+	keySetMu.RLock()
+	defer keySetMu.RUnlock()
+	if keySet == nil {
+		return nil, errors.New("JWT key set not loaded")
+	}
+	return keySet, nil
+}
+
+// SetKeySet replaces the process wide key set
+func SetKeySet(ks *KeySet) {
+	keySetMu.Lock()
+	defer keySetMu.Unlock()
+	keySet = ks
+}
+
+// LoadKeySet builds a key set from a JSON key file or, without one, from the legacy HS256 secret as the only key.
+// The legacy secret is ignored when a key file is given, so rotating to the file retires it; to keep accepting
+// tokens signed with it, list it in the file, e.g. with kid "default" and secret_env JWT_SECRET.
+func LoadKeySet(keysFile, legacySecret string) (*KeySet, error) {
+	ks := &KeySet{keys: map[string]*SigningKey{}}
+
+	if keysFile == "" && legacySecret != "" {
+		ks.keys[legacyKeyID] = &SigningKey{ID: legacyKeyID, Algorithm: AlgHS256, secret: []byte(legacySecret)}
+	}
+
+	activeID := legacyKeyID
+	if keysFile != "" {
+		data, err := os.ReadFile(keysFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT key file: %w", err)
+		}
+		var file keySetFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse JWT key file: %w", err)
+		}
+
+		baseDir := filepath.Dir(keysFile)
+		for _, entry := range file.Keys {
+			if entry.ID == "" {
+				return nil, errors.New("JWT key without kid")
+			}
+			if _, exists := ks.keys[entry.ID]; exists {
+				return nil, fmt.Errorf("duplicate JWT kid %q", entry.ID)
+			}
+
+			key := &SigningKey{ID: entry.ID, Algorithm: entry.Algorithm, Retired: entry.Retired}
+			switch entry.Algorithm {
+			case AlgHS256:
+				secret := entry.Secret
+				if entry.SecretEnv != "" {
+					secret = os.Getenv(entry.SecretEnv)
+				}
+				if secret == "" {
+					return nil, fmt.Errorf("JWT key %q has no secret", entry.ID)
+				}
+				key.secret = []byte(secret)
+			case AlgRS256, AlgEdDSA:
+				if err := loadAsymmetricKey(key, resolvePath(baseDir, entry.PrivateKeyFile), resolvePath(baseDir, entry.PublicKeyFile)); err != nil {
+					return nil, err
+				}
+			default:
+				return nil, fmt.Errorf("JWT key %q has unsupported alg %q", entry.ID, entry.Algorithm)
+			}
+			ks.keys[entry.ID] = key
+		}
+
+		if file.Active != "" {
+			activeID = file.Active
+		}
+	}
+
+	active, ok := ks.keys[activeID]
+	if !ok {
+		if keysFile == "" {
+			return nil, errors.New("Secret not provided")
+		}
+		return nil, fmt.Errorf("active JWT key %q not found", activeID)
+	}
+	if active.Retired {
+		return nil, fmt.Errorf("active JWT key %q is retired", activeID)
+	}
+	if _, err := active.signingKey(); err != nil {
+		return nil, err
+	}
+	ks.active = active
+
+	return ks, nil
+}
+
+func resolvePath(baseDir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// loadAsymmetricKey reads a PEM private key, or only a public key for verification-only keys
+func loadAsymmetricKey(key *SigningKey, privateFile, publicFile string) error {
+	if privateFile != "" {
+		block, err := readPEM(privateFile)
+		if err != nil {
+			return err
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		}
+		if err != nil {
+			return fmt.Errorf("JWT key %q: invalid private key: %w", key.ID, err)
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return fmt.Errorf("JWT key %q: unsupported private key", key.ID)
+		}
+		key.private = signer
+		key.public = signer.Public()
+	} else if publicFile != "" {
+		block, err := readPEM(publicFile)
+		if err != nil {
+			return err
+		}
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("JWT key %q: invalid public key: %w", key.ID, err)
+		}
+		key.public = parsed
+	} else {
+		return fmt.Errorf("JWT key %q needs private_key_file or public_key_file", key.ID)
+	}
+
+	switch key.public.(type) {
+	case *rsa.PublicKey:
+		if key.Algorithm != AlgRS256 {
+			return fmt.Errorf("JWT key %q: RSA key used with %s", key.ID, key.Algorithm)
+		}
+	case ed25519.PublicKey:
+		if key.Algorithm != AlgEdDSA {
+			return fmt.Errorf("JWT key %q: Ed25519 key used with %s", key.ID, key.Algorithm)
+		}
+	default:
+		return fmt.Errorf("JWT key %q: unsupported key type", key.ID)
+	}
+	return nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in %s", path)
+	}
+	return block, nil
+}
+
+// Active returns the key used to sign new tokens
+func (ks *KeySet) Active() *SigningKey {
+	return ks.active
+}
+
+// Lookup returns the verification key for a kid; tokens without kid use the legacy key
+func (ks *KeySet) Lookup(kid string) (*SigningKey, bool) {
+	if kid == "" {
+		kid = legacyKeyID
+	}
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+// Algorithms lists the algorithms accepted for verification
+func (ks *KeySet) Algorithms() []string {
+	seen := map[string]bool{}
+	algs := []string{}
+	for _, key := range ks.keys {
+		if !seen[key.Algorithm] {
+			seen[key.Algorithm] = true
+			algs = append(algs, key.Algorithm)
+		}
+	}
+	return algs
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the set. Symmetric keys are never published.
+func (ks *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range ks.keys {
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "RSA",
+				Use:       "sig",
+				Algorithm: key.Algorithm,
+				KeyID:     key.ID,
+				N:         base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "OKP",
+				Use:       "sig",
+				Algorithm: key.Algorithm,
+				KeyID:     key.ID,
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+	return set
+}
@@ -0,0 +1,62 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadKeySetLegacySecret(t *testing.T) {
+	t.Setenv("TEST_JWT_SECRET", "old-secret")
+
+	tests := []struct {
+		name     string
+		keysFile string
+		legacy   bool
+		active   string
+	}{
+		{name: "without a key file", legacy: true, active: "default"},
+		{
+			name:     "key file replaces the secret",
+			keysFile: `{"active": "new", "keys": [{"kid": "new", "alg": "HS256", "secret": "new-secret"}]}`,
+			active:   "new",
+		},
+		{
+			name:     "key file listing the secret",
+			keysFile: `{"active": "new", "keys": [{"kid": "new", "alg": "HS256", "secret": "new-secret"}, {"kid": "default", "alg": "HS256", "secret_env": "TEST_JWT_SECRET", "retired": true}]}`,
+			legacy:   true,
+			active:   "new",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keysFile := ""
+			if tt.keysFile != "" {
+				keysFile = filepath.Join(t.TempDir(), "keys.json")
+				if err := os.WriteFile(keysFile, []byte(tt.keysFile), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ks, err := LoadKeySet(keysFile, "old-secret")
+			if err != nil {
+				t.Fatalf("LoadKeySet() error = %v", err)
+			}
+			if ks.Active().ID != tt.active {
+				t.Errorf("active key = %q, want %q", ks.Active().ID, tt.active)
+			}
+
+			// Tokens without a kid predate the key set and were signed with the legacy secret
+			for _, kid := range []string{"default", ""} {
+				key, ok := ks.Lookup(kid)
+				if ok != tt.legacy {
+					t.Fatalf("Lookup(%q) found a key = %v, want %v", kid, ok, tt.legacy)
+				}
+				if ok && string(key.secret) != "old-secret" {
+					t.Errorf("Lookup(%q) returned the secret %q, want the legacy one", kid, key.secret)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package pkg
+
+import "sync/atomic"
+
+// shuttingDown is set once the process has started a graceful shutdown
+var shuttingDown atomic.Bool
+
+// BeginShutdown marks the process as shutting down so readiness checks start failing
+func BeginShutdown() {
+	shuttingDown.Store(true)
+}
+
+// ShuttingDown reports whether a graceful shutdown has started
+func ShuttingDown() bool {
+	return shuttingDown.Load()
+}
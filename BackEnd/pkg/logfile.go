@@ -0,0 +1,277 @@
+package pkg
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat names rotated files so they sort chronologically
+const backupTimeFormat = "20060102T150405.000"
+
+// LogRotation configures when the log file is rotated and how many rotated files are kept.
+// A zero MaxSize or Interval disables that trigger, and a zero MaxBackups keeps every file.
+type LogRotation struct {
+	MaxSize    int64
+	Interval   time.Duration
+	MaxBackups int
+	Compress   bool
+}
+
+// RotatingFile is an io.Writer over a log file that rotates by size and age.
+// Rotated files are renamed to <name>-<timestamp><ext> and optionally gzipped in the background.
+// All methods are safe for concurrent use.
+type RotatingFile struct {
+	path     string
+	rotation LogRotation
+
+	mu           sync.Mutex
+	file         *os.File
+	size         int64
+	nextRotation time.Time
+
+	// background tracks compression and pruning so Close can wait for them
+	background sync.WaitGroup
+}
+
+// OpenRotatingFile opens path for appending, creating its directory if needed
+func OpenRotatingFile(path string, rotation LogRotation) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	r := &RotatingFile{path: path, rotation: rotation}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens the log file and resets the size and the next scheduled rotation. Callers hold mu.
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	r.file = file
+	r.size = info.Size()
+	if r.rotation.Interval > 0 {
+		r.nextRotation = time.Now().Truncate(r.rotation.Interval).Add(r.rotation.Interval)
+	}
+	return nil
+}
+
+// Write appends p to the log file, rotating first when it is too large or too old
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if r.dueForRotation(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			// Keep logging to whichever file is open rather than dropping lines
+			fmt.Fprintf(os.Stderr, "log rotation failed: %v\n", err)
+			if r.file == nil {
+				return 0, err
+			}
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// dueForRotation reports whether writing n more bytes should start a new file. Callers hold mu.
+func (r *RotatingFile) dueForRotation(n int64) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.rotation.MaxSize > 0 && r.size+n > r.rotation.MaxSize {
+		return true
+	}
+	return r.rotation.Interval > 0 && !time.Now().Before(r.nextRotation)
+}
+
+// rotate renames the current file aside and opens a new one. Callers hold mu.
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to close log file: %v\n", err)
+	}
+	r.file = nil
+
+	ext := filepath.Ext(r.path)
+	backup := strings.TrimSuffix(r.path, ext) + "-" + time.Now().Format(backupTimeFormat) + ext
+	renameErr := os.Rename(r.path, backup)
+
+	if err := r.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return fmt.Errorf("failed to rename log file: %w", renameErr)
+	}
+
+	r.background.Add(1)
+	go func() {
+		defer r.background.Done()
+		if r.rotation.Compress {
+			if err := compressFile(backup); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to compress %s: %v\n", backup, err)
+			}
+		}
+		r.prune()
+	}()
+	return nil
+}
+
+// Reopen closes and reopens the log file so an external logrotate can move it away
+func (r *RotatingFile) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file != nil {
+		if err := r.file.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to close log file: %v\n", err)
+		}
+		r.file = nil
+	}
+	return r.open()
+}
+
+// Sync flushes the log file to disk
+func (r *RotatingFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	return r.file.Sync()
+}
+
+// Close waits for background compression and closes the log file
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	file := r.file
+	r.file = nil
+	r.mu.Unlock()
+
+	r.background.Wait()
+
+	if file == nil {
+		return nil
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// prune removes the oldest rotated files beyond MaxBackups
+func (r *RotatingFile) prune() {
+	if r.rotation.MaxBackups <= 0 {
+		return
+	}
+
+	backups, err := r.backups()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to list rotated logs: %v\n", err)
+		return
+	}
+	if len(backups) <= r.rotation.MaxBackups {
+		return
+	}
+	for _, backup := range backups[:len(backups)-r.rotation.MaxBackups] {
+		for _, name := range []string{backup, backup + ".gz"} {
+			if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+				fmt.Fprintf(os.Stderr, "failed to remove rotated log %s: %v\n", name, err)
+			}
+		}
+	}
+}
+
+// backups lists rotated files without their .gz suffix, oldest first, so a file being compressed is counted once
+func (r *RotatingFile) backups() ([]string, error) {
+	dir := filepath.Dir(r.path)
+	ext := filepath.Ext(r.path)
+	prefix := strings.TrimSuffix(filepath.Base(r.path), ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	backups := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stem := strings.TrimSuffix(name, ".gz")
+		if !strings.HasSuffix(stem, ext) {
+			continue
+		}
+		if _, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(stem, prefix), ext)); err != nil {
+			continue
+		}
+		if seen[stem] {
+			continue
+		}
+		seen[stem] = true
+		backups = append(backups, filepath.Join(dir, stem))
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// compressFile gzips path to path.gz and removes the original
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	gz.Name = filepath.Base(path)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	src.Close()
+	return os.Remove(path)
+}
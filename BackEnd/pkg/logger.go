@@ -1,12 +1,15 @@
 package pkg
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
 	"time"
 )
 
@@ -18,14 +21,25 @@ const (
 	LevelDebug
 )
 
-// Logger represents the logging system
+// Log output formats
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// LoggerConfig selects the level, output format, log file, its rotation and what is redacted
+type LoggerConfig struct {
+	Level     int
+	Format    string
+	File      string
+	Rotation  LogRotation
+	Redaction RedactionConfig
+}
+
+// Logger writes structured log records through log/slog
 type Logger struct {
-	level      int
-	errorLog   *log.Logger
-	warnLog    *log.Logger
-	infoLog    *log.Logger
-	debugLog   *log.Logger
-	fileHandle *os.File
+	slog *slog.Logger
+	file *RotatingFile
 }
 
 var (
@@ -33,137 +47,168 @@ var (
 	defaultLogger *Logger
 )
 
-// InitLogger creates a new logger with the specified level and log file path
-func InitLogger(level int, logFilePath string) (*Logger, error) {
-	logger := &Logger{
-		level: level,
-	}
+// loggerContextKey stores a request scoped *Logger in a context
+type loggerContextKey struct{}
 
-	// Ensure log directory exists
-	if logFilePath != "" {
-		logDir := filepath.Dir(logFilePath)
-		if err := os.MkdirAll(logDir, 0755); err != nil {
-			return nil, fmt.Errorf("failed to create log directory: %w", err)
-		}
+// InitLogger creates a new logger writing to stdout and, when set, the log file
+func InitLogger(cfg LoggerConfig) (*Logger, error) {
+	logger := &Logger{}
+
+	redactor, err := newRedactor(cfg.Redaction)
+	if err != nil {
+		return nil, err
+	}
 
-		// Open the log file, creating it if it doesn't exist
-		fileHandle, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	var out io.Writer = os.Stdout
+	if cfg.File != "" {
+		// Open the log file, creating it and its directory if they don't exist
+		file, err := OpenRotatingFile(cfg.File, cfg.Rotation)
 		if err != nil {
-			return nil, fmt.Errorf("failed to open log file: %w", err)
+			return nil, err
 		}
 
-		// Save file handle for later closure
-		logger.fileHandle = fileHandle
-
-		// Set up multi-writer for both console and file
-		multiWriter := io.MultiWriter(os.Stdout, fileHandle)
+		// Save the file for reopening and closure
+		logger.file = file
+		out = io.MultiWriter(os.Stdout, file)
+	}
 
-		// Initialize loggers with appropriate prefixes
-		logger.errorLog = log.New(multiWriter, "\033[31mERROR\033[0m: ", log.Ldate|log.Ltime)
-		logger.warnLog = log.New(multiWriter, "\033[33mWARN\033[0m:  ", log.Ldate|log.Ltime)
-		logger.infoLog = log.New(multiWriter, "\033[36mINFO\033[0m:  ", log.Ldate|log.Ltime)
-		logger.debugLog = log.New(multiWriter, "\033[35mDEBUG\033[0m: ", log.Ldate|log.Ltime)
+	options := &slog.HandlerOptions{
+		Level:     slogLevel(cfg.Level),
+		AddSource: true,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			return redactor.replaceAttr(groups, shortSource(groups, attr))
+		},
+	}
+	var handler slog.Handler
+	if cfg.Format == LogFormatText {
+		handler = slog.NewTextHandler(out, options)
 	} else {
-		// Initialize loggers with console output only
-		logger.errorLog = log.New(os.Stdout, "\033[31mERROR\033[0m: ", log.Ldate|log.Ltime)
-		logger.warnLog = log.New(os.Stdout, "\033[33mWARN\033[0m:  ", log.Ldate|log.Ltime)
-		logger.infoLog = log.New(os.Stdout, "\033[36mINFO\033[0m:  ", log.Ldate|log.Ltime)
-		logger.debugLog = log.New(os.Stdout, "\033[35mDEBUG\033[0m: ", log.Ldate|log.Ltime)
+		handler = slog.NewJSONHandler(out, options)
 	}
+	logger.slog = slog.New(handler)
 
-	// Set as default logger if none exists yet
+	// Set as default logger if none exists yet. Libraries using the standard log package go through it too.
 	if defaultLogger == nil {
 		defaultLogger = logger
+		slog.SetDefault(logger.slog)
 	}
 
 	return logger, nil
 }
 
-// Close closes the log file if one was opened
-func (l *Logger) Close() error {
-	if l.fileHandle != nil {
-		return l.fileHandle.Close()
+// slogLevel maps a pkg log level to its slog level
+func slogLevel(level int) slog.Level {
+	switch level {
+	case LevelError:
+		return slog.LevelError
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelInfo:
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
 	}
-	return nil
 }
 
-// LogWithCaller adds file and line information to the log message
-func logWithCaller(msg string) string {
-	_, file, line, ok := runtime.Caller(2)
+// shortSource reduces the source attribute to file:line
+func shortSource(_ []string, attr slog.Attr) slog.Attr {
+	if attr.Key != slog.SourceKey {
+		return attr
+	}
+	source, ok := attr.Value.Any().(*slog.Source)
 	if !ok {
-		file = "unknown"
-		line = 0
+		return attr
+	}
+	return slog.String(slog.SourceKey, filepath.Base(source.File)+":"+strconv.Itoa(source.Line))
+}
+
+// Reopen reopens the log file after an external tool such as logrotate has moved it
+func (l *Logger) Reopen() error {
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Reopen()
+}
+
+// Close waits for rotated files to be compressed, then flushes and closes the log file if one was opened
+func (l *Logger) Close() error {
+	if l.file == nil {
+		return nil
 	}
-	// Extract just the filename without the full path
-	file = filepath.Base(file)
-	return fmt.Sprintf("%s (%s:%d)", msg, file, line)
+	return l.file.Close()
+}
+
+// With returns a logger that adds the key/value pairs to every record
+func (l *Logger) With(args ...any) *Logger {
+	return &Logger{slog: l.slog.With(args...)}
+}
+
+// log writes a record attributed to the caller of the exported logging function
+func (l *Logger) log(level slog.Level, msg string, err error, fields map[string]any) {
+	ctx := context.Background()
+	if !l.slog.Enabled(ctx, level) {
+		return
+	}
+
+	// Skip runtime.Callers, log and the exported wrapper
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+
+	record := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	if err != nil {
+		record.AddAttrs(slog.String("error", err.Error()))
+	}
+	// Sort keys so lines with the same fields always look alike
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		record.AddAttrs(slog.Any(key, fields[key]))
+	}
+
+	_ = l.slog.Handler().Handle(ctx, record)
 }
 
 // Error logs an error message
 func (l *Logger) Error(msg string, err error) {
-	if l.level >= LevelError {
-		if err != nil {
-			l.errorLog.Println(logWithCaller(fmt.Sprintf("%s: %v", msg, err)))
-		} else {
-			l.errorLog.Println(logWithCaller(msg))
-		}
-	}
+	l.log(slog.LevelError, msg, err, nil)
 }
 
 // Warn logs a warning message
 func (l *Logger) Warn(msg string) {
-	if l.level >= LevelWarn {
-		l.warnLog.Println(logWithCaller(msg))
-	}
+	l.log(slog.LevelWarn, msg, nil, nil)
 }
 
 // Info logs an informational message
 func (l *Logger) Info(msg string) {
-	if l.level >= LevelInfo {
-		l.infoLog.Println(msg)
-	}
+	l.log(slog.LevelInfo, msg, nil, nil)
 }
 
 // Debug logs a debug message
 func (l *Logger) Debug(msg string) {
-	if l.level >= LevelDebug {
-		l.debugLog.Println(logWithCaller(msg))
-	}
+	l.log(slog.LevelDebug, msg, nil, nil)
 }
 
 // ErrorWithFields logs an error with additional fields
-func (l *Logger) ErrorWithFields(msg string, err error, fields map[string]interface{}) {
-	if l.level >= LevelError {
-		fieldsStr := formatFields(fields)
-		if err != nil {
-			l.errorLog.Println(logWithCaller(fmt.Sprintf("%s: %v %s", msg, err, fieldsStr)))
-		} else {
-			l.errorLog.Println(logWithCaller(fmt.Sprintf("%s %s", msg, fieldsStr)))
-		}
-	}
+func (l *Logger) ErrorWithFields(msg string, err error, fields map[string]any) {
+	l.log(slog.LevelError, msg, err, fields)
 }
 
-// InfoWithFields logs info with additional fields
-func (l *Logger) InfoWithFields(msg string, fields map[string]interface{}) {
-	if l.level >= LevelInfo {
-		fieldsStr := formatFields(fields)
-		l.infoLog.Println(fmt.Sprintf("%s %s", msg, fieldsStr))
-	}
+// WarnWithFields logs a warning with additional fields
+func (l *Logger) WarnWithFields(msg string, fields map[string]any) {
+	l.log(slog.LevelWarn, msg, nil, fields)
 }
 
-// formatFields formats a map of fields into a string
-func formatFields(fields map[string]interface{}) string {
-	if len(fields) == 0 {
-		return ""
-	}
+// InfoWithFields logs info with additional fields
+func (l *Logger) InfoWithFields(msg string, fields map[string]any) {
+	l.log(slog.LevelInfo, msg, nil, fields)
+}
 
-	result := "{"
-	for k, v := range fields {
-		result += fmt.Sprintf("%s:%v, ", k, v)
-	}
-	// Remove the trailing comma and space
-	result = result[:len(result)-2] + "}"
-	return result
+// DebugWithFields logs a debug message with additional fields
+func (l *Logger) DebugWithFields(msg string, fields map[string]any) {
+	l.log(slog.LevelDebug, msg, nil, fields)
 }
 
 // GetLogger returns the default logger
@@ -171,55 +216,87 @@ func GetLogger() *Logger {
 	return defaultLogger
 }
 
+// ContextWithLogger returns a copy of ctx carrying the logger
+func ContextWithLogger(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// WithLogAttrs returns a copy of ctx whose logger adds the key/value pairs to every record
+func WithLogAttrs(ctx context.Context, args ...any) context.Context {
+	return ContextWithLogger(ctx, LoggerFromContext(ctx).With(args...))
+}
+
+// LoggerFromContext returns the logger bound to ctx, falling back to the default logger.
+// Inside a request it carries the request ID, route, trace ID and, once authenticated, the user ID.
+func LoggerFromContext(ctx context.Context) *Logger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(*Logger); ok {
+		return logger
+	}
+	if defaultLogger != nil {
+		return defaultLogger
+	}
+	return &Logger{slog: slog.Default()}
+}
+
 // Error logs an error message using the default logger
 func Error(msg string, err error) {
 	if defaultLogger != nil {
-		defaultLogger.Error(msg, err)
+		defaultLogger.log(slog.LevelError, msg, err, nil)
 	}
 }
 
 // Warn logs a warning message using the default logger
 func Warn(msg string) {
 	if defaultLogger != nil {
-		defaultLogger.Warn(msg)
+		defaultLogger.log(slog.LevelWarn, msg, nil, nil)
 	}
 }
 
 // Info logs an informational message using the default logger
 func Info(msg string) {
 	if defaultLogger != nil {
-		defaultLogger.Info(msg)
+		defaultLogger.log(slog.LevelInfo, msg, nil, nil)
 	}
 }
 
 // Debug logs a debug message using the default logger
 func Debug(msg string) {
 	if defaultLogger != nil {
-		defaultLogger.Debug(msg)
+		defaultLogger.log(slog.LevelDebug, msg, nil, nil)
 	}
 }
 
 // ErrorWithFields logs an error with fields using the default logger
 func ErrorWithFields(msg string, err error, fields map[string]any) {
 	if defaultLogger != nil {
-		defaultLogger.ErrorWithFields(msg, err, fields)
+		defaultLogger.log(slog.LevelError, msg, err, fields)
 	}
 }
 
-// LogHTTPRequest logs details about an HTTP request with its processing time
-func LogHTTPRequest(method, path, ip string, status int, duration time.Duration) {
-	if defaultLogger == nil || defaultLogger.level < LevelInfo {
-		return
+// WarnWithFields logs a warning with fields using the default logger
+func WarnWithFields(msg string, fields map[string]any) {
+	if defaultLogger != nil {
+		defaultLogger.log(slog.LevelWarn, msg, nil, fields)
 	}
+}
 
-	fields := map[string]interface{}{
-		"method":   method,
-		"path":     path,
-		"ip":       ip,
-		"status":   status,
-		"duration": duration,
+// InfoWithFields logs info with fields using the default logger
+func InfoWithFields(msg string, fields map[string]any) {
+	if defaultLogger != nil {
+		defaultLogger.log(slog.LevelInfo, msg, nil, fields)
 	}
-	defaultLogger.InfoWithFields("HTTP Request", fields)
+}
+
+// LogHTTPRequest logs details about an HTTP request with its processing time.
+// Request ID, route and trace ID come from the logger bound to ctx.
+func LogHTTPRequest(ctx context.Context, method, path, ip string, status int, duration time.Duration) {
+	LoggerFromContext(ctx).log(slog.LevelInfo, "HTTP Request", nil, map[string]any{
+		"method":     method,
+		"path":       path,
+		"ip":         ip,
+		"status":     status,
+		"durationMs": float64(duration.Microseconds()) / 1000,
+	})
 }
 
 // LogPanic logs a panic and recovers from it
@@ -0,0 +1,118 @@
+package pkg
+
+import (
+	"fmt"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Mail is a plain text email message
+type Mail struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails
+type Mailer interface {
+	Send(mail Mail) error
+}
+
+// MailConfig selects and configures a mailer
+type MailConfig struct {
+	Driver       string
+	From         string
+	Dir          string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// NewMailer creates the mailer selected by cfg.Driver ("smtp" or "file")
+func NewMailer(cfg MailConfig) Mailer {
+	switch cfg.Driver {
+	case "smtp":
+		return &SMTPMailer{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.From,
+		}
+	default:
+		return &FileMailer{
+			Dir:  cfg.Dir,
+			From: cfg.From,
+		}
+	}
+}
+
+// buildMessage renders a mail with the headers required by RFC 5322
+func buildMessage(from string, mail Mail) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + strings.Join(mail.To, ", ") + "\r\n")
+	b.WriteString("Subject: " + mail.Subject + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(mail.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// SMTPMailer sends mail through an SMTP server such as MailHog
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Send delivers the mail over SMTP, authenticating only when credentials are set
+func (m *SMTPMailer) Send(mail Mail) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	addr := m.Host + ":" + m.Port
+	if err := smtp.SendMail(addr, auth, envelopeAddress(m.From), mail.To, buildMessage(m.From, mail)); err != nil {
+		return fmt.Errorf("failed to send mail via %s: %w", addr, err)
+	}
+	return nil
+}
+
+// envelopeAddress extracts the bare address from "Name <address>"
+func envelopeAddress(from string) string {
+	if start := strings.LastIndex(from, "<"); start >= 0 {
+		if end := strings.LastIndex(from, ">"); end > start {
+			return from[start+1 : end]
+		}
+	}
+	return from
+}
+
+// FileMailer writes each mail as an .eml file, useful for development without an SMTP server
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+// Send writes the mail into the configured directory
+func (m *FileMailer) Send(mail Mail) error {
+	if err := os.MkdirAll(m.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create mail directory: %w", err)
+	}
+
+	name := fmt.Sprintf("%s.eml", time.Now().Format("20060102-150405.000000000"))
+	if err := os.WriteFile(filepath.Join(m.Dir, name), buildMessage(m.From, mail), 0600); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+	return nil
+}
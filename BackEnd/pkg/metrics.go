@@ -0,0 +1,113 @@
+package pkg
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Cache names and results used as metric labels
+const (
+	CacheBlogList = "blog_list"
+	CacheBlogSlug = "blog_slug"
+
+	LoginSuccess = "success"
+	LoginFailure = "failure"
+	LoginLocked  = "locked"
+)
+
+// metricsRegistry holds every collector exposed on /metrics
+var metricsRegistry = prometheus.NewRegistry()
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "blogku_http_requests_total",
+		Help: "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "blogku_http_request_duration_seconds",
+		Help:    "HTTP request latency by method, route template and status code.",
+		Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"method", "route", "status"})
+
+	httpRequestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "blogku_http_requests_in_flight",
+		Help: "HTTP requests currently being served.",
+	})
+
+	cacheRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "blogku_cache_requests_total",
+		Help: "Redis cache lookups by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+
+	uploadSizeBytes = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "blogku_upload_size_bytes",
+		Help:    "Size of accepted image uploads.",
+		Buckets: prometheus.ExponentialBuckets(16*1024, 4, 8), // 16 KiB to 256 MiB
+	})
+
+	loginsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "blogku_logins_total",
+		Help: "Login attempts by result (success, failure or locked).",
+	}, []string{"result"})
+)
+
+func init() {
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestsTotal,
+		httpRequestDuration,
+		httpRequestsInFlight,
+		cacheRequestsTotal,
+		uploadSizeBytes,
+		loginsTotal,
+	)
+}
+
+// MetricsHandler serves the registered metrics in the Prometheus text format
+func MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{})
+}
+
+// RegisterDBStats exposes the connection pool statistics of db
+func RegisterDBStats(db *sql.DB, name string) {
+	metricsRegistry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// TrackHTTPRequest marks a request as in flight and returns a function that records it once finished
+func TrackHTTPRequest() func(method, route string, status int) {
+	start := time.Now()
+	httpRequestsInFlight.Inc()
+	return func(method, route string, status int) {
+		httpRequestsInFlight.Dec()
+		code := strconv.Itoa(status)
+		httpRequestsTotal.WithLabelValues(method, route, code).Inc()
+		httpRequestDuration.WithLabelValues(method, route, code).Observe(time.Since(start).Seconds())
+	}
+}
+
+// CountCacheLookup records a cache hit or miss
+func CountCacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheRequestsTotal.WithLabelValues(cache, result).Inc()
+}
+
+// ObserveUpload records the size of an accepted upload
+func ObserveUpload(size int64) {
+	uploadSizeBytes.Observe(float64(size))
+}
+
+// CountLogin records the result of a login attempt
+func CountLogin(result string) {
+	loginsTotal.WithLabelValues(result).Inc()
+}
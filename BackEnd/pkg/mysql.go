@@ -3,58 +3,44 @@ package pkg
 import (
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/go-sql-driver/mysql" // Import the MySQL driver
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 var DB *sql.DB
 
-func Connect() (*sql.DB, error) {
-	// Get the environment variables for the database connection
-	dbUser := os.Getenv("DBUSER")
-	dbPass := os.Getenv("DBPASS")
-	dbHost := os.Getenv("DBHOST")
-	dbPort := os.Getenv("DBPORT")
-	dbName := os.Getenv("DBNAME")
-
-	// Validate and use defaults if necessary
-	if dbHost == "" {
-		dbHost = "localhost"
-		log.Println("DBHOST not set, using default:", dbHost)
-	}
-
-	if dbPort == "" {
-		dbPort = "3306" // Default MySQL port
-		log.Println("DBPORT not set, using default:", dbPort)
-	}
-
-	if dbName == "" {
-		return nil, fmt.Errorf("DBNAME environment variable is required")
-	}
-
-	// Create the connection string for MySQL
-	dbString := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&multiStatements=true",
-		dbUser, dbPass, dbHost, dbPort, dbName)
+// MySQLConfig holds the connection string and pool settings for MySQL
+type MySQLConfig struct {
+	DSN             string
+	User            string
+	Host            string
+	Port            int
+	Name            string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
 
+func Connect(cfg MySQLConfig) (*sql.DB, error) {
 	// Print connection info for debugging (hide password)
-	connectionInfo := fmt.Sprintf("Connecting to MySQL: User: %s, Host: %s, Port: %s, Database: %s",
-		dbUser, dbHost, dbPort, dbName)
-	log.Println(connectionInfo)
+	connectionInfo := fmt.Sprintf("Connecting to MySQL: User: %s, Host: %s, Port: %d, Database: %s",
+		cfg.User, cfg.Host, cfg.Port, cfg.Name)
+	Info(connectionInfo)
 
-	// Open the connection to the MySQL database
+	// Open the connection to the MySQL database, recording a span for every query
 	var err error
-	DB, err = sql.Open("mysql", dbString)
+	DB, err = otelsql.Open("mysql", cfg.DSN, otelsql.WithAttributes(semconv.DBSystemMySQL, semconv.DBNamespace(cfg.Name)))
 	if err != nil {
 		return nil, err
 	}
 
 	// Configure connection pool
-	DB.SetMaxOpenConns(25)
-	DB.SetMaxIdleConns(25)
-	DB.SetConnMaxLifetime(5 * time.Minute)
+	DB.SetMaxOpenConns(cfg.MaxOpenConns)
+	DB.SetMaxIdleConns(cfg.MaxIdleConns)
+	DB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
 	// Check if the database is reachable
 	err = DB.Ping()
@@ -62,6 +48,6 @@ func Connect() (*sql.DB, error) {
 		return nil, fmt.Errorf("ping failed: %w", err)
 	}
 
-	log.Println("MySQL DB connected successfully")
+	Info("MySQL DB connected successfully")
 	return DB, nil
 }
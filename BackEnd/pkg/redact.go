@@ -0,0 +1,205 @@
+package pkg
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// redactedValue replaces the value of every attribute whose key matches a redaction pattern
+const redactedValue = "[REDACTED]"
+
+// maxRedactDepth bounds how deep nested maps, structs, slices and pointers are expanded, which also stops reference cycles
+const maxRedactDepth = 5
+
+// truncatedValue replaces values nested deeper than maxRedactDepth
+const truncatedValue = "[TRUNCATED]"
+
+// DefaultRedactKeys are the key patterns redacted by default
+var DefaultRedactKeys = []string{"password", "token", "secret", "authorization", "cookie"}
+
+// emailPattern finds email addresses anywhere in a message or string value
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// RedactionConfig controls which log attributes are masked before they are written.
+// Keys are case-insensitive substrings: "token" also matches "csrfToken" and "resetTokenHash".
+// Hashed emails and IPs stay comparable between lines without revealing the value.
+type RedactionConfig struct {
+	Keys       []string
+	HashEmails bool
+	HashIPs    bool
+	// HashKey keys the HMAC used for hashing; a random key is used when empty,
+	// so hashes only correlate within one process
+	HashKey string
+}
+
+// redactor masks sensitive attributes in slog records
+type redactor struct {
+	keys       []string
+	hashEmails bool
+	hashIPs    bool
+	hashKey    []byte
+}
+
+func newRedactor(cfg RedactionConfig) (*redactor, error) {
+	r := &redactor{
+		hashEmails: cfg.HashEmails,
+		hashIPs:    cfg.HashIPs,
+		hashKey:    []byte(cfg.HashKey),
+	}
+	for _, key := range cfg.Keys {
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			r.keys = append(r.keys, key)
+		}
+	}
+	if len(r.hashKey) == 0 {
+		r.hashKey = make([]byte, 32)
+		if _, err := rand.Read(r.hashKey); err != nil {
+			return nil, fmt.Errorf("failed to generate log hash key: %w", err)
+		}
+	}
+	return r, nil
+}
+
+// replaceAttr is the slog.HandlerOptions.ReplaceAttr hook. It also sees the built-in msg attribute,
+// so emails embedded in messages and error strings are hashed as well. Maps, structs and slices are
+// turned into groups, which the handler passes back here member by member, so nested keys are masked too.
+func (r *redactor) replaceAttr(groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) == 0 {
+		switch attr.Key {
+		case slog.TimeKey, slog.LevelKey, slog.SourceKey:
+			return attr
+		}
+	}
+
+	key := strings.ToLower(attr.Key)
+	if attr.Key != slog.MessageKey && r.sensitiveKey(key) {
+		return slog.String(attr.Key, redactedValue)
+	}
+
+	if attr.Value.Kind() == slog.KindAny {
+		if err, ok := attr.Value.Any().(error); ok {
+			attr = slog.String(attr.Key, err.Error())
+		} else if group, ok := expandValue(attr.Value.Any()); ok {
+			if len(groups) >= maxRedactDepth {
+				return slog.String(attr.Key, truncatedValue)
+			}
+			return slog.Attr{Key: attr.Key, Value: group}
+		}
+	}
+
+	if attr.Value.Kind() != slog.KindString {
+		return attr
+	}
+	value := attr.Value.String()
+
+	if r.hashIPs && (key == "ip" || strings.HasSuffix(key, "ip")) {
+		if net.ParseIP(value) != nil {
+			return slog.String(attr.Key, r.hash("ip", value))
+		}
+	}
+	if r.hashEmails {
+		if key == "email" || key == "identifier" {
+			return slog.String(attr.Key, r.hash("id", strings.ToLower(value)))
+		}
+		if strings.Contains(value, "@") {
+			value = emailPattern.ReplaceAllStringFunc(value, func(email string) string {
+				return r.hash("email", strings.ToLower(email))
+			})
+			return slog.String(attr.Key, value)
+		}
+	}
+	return attr
+}
+
+// expandValue turns a map with string keys, a struct or a slice into a group value so every member
+// goes through replaceAttr. Struct fields are named by their json tag. Values that format themselves,
+// such as time.Time, byte slices and slices of numbers are left alone. A chain of more than
+// maxRedactDepth pointers, which may point back to itself, is replaced by a placeholder.
+func expandValue(v any) (slog.Value, bool) {
+	switch v.(type) {
+	case nil, fmt.Stringer, json.Marshaler, encoding.TextMarshaler:
+		return slog.Value{}, false
+	}
+
+	rv := reflect.ValueOf(v)
+	for depth := 0; rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface; depth++ {
+		if rv.IsNil() {
+			return slog.Value{}, false
+		}
+		if depth == maxRedactDepth {
+			return slog.StringValue(truncatedValue), true
+		}
+		rv = rv.Elem()
+	}
+
+	attrs := []slog.Attr{}
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return slog.Value{}, false
+		}
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+		for _, key := range keys {
+			attrs = append(attrs, slog.Any(key.String(), rv.MapIndex(key).Interface()))
+		}
+	case reflect.Struct:
+		fields := rv.Type()
+		for i := 0; i < fields.NumField(); i++ {
+			field := fields.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := field.Name
+			if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag == "-" {
+				continue
+			} else if tag != "" {
+				name = tag
+			}
+			attrs = append(attrs, slog.Any(name, rv.Field(i).Interface()))
+		}
+	case reflect.Slice, reflect.Array:
+		switch rv.Type().Elem().Kind() {
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+			return slog.Value{}, false
+		}
+		for i := 0; i < rv.Len(); i++ {
+			attrs = append(attrs, slog.Any(strconv.Itoa(i), rv.Index(i).Interface()))
+		}
+	default:
+		return slog.Value{}, false
+	}
+	return slog.GroupValue(attrs...), true
+}
+
+// sensitiveKey reports whether the lowercased key matches a redaction pattern
+func (r *redactor) sensitiveKey(key string) bool {
+	for _, pattern := range r.keys {
+		if strings.Contains(key, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// hash returns a short keyed hash such as email:3f9a2c1b7d4e
+func (r *redactor) hash(kind, value string) string {
+	mac := hmac.New(sha256.New, r.hashKey)
+	mac.Write([]byte(value))
+	return kind + ":" + hex.EncodeToString(mac.Sum(nil))[:12]
+}
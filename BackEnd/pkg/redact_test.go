@@ -0,0 +1,258 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// hashPattern matches the keyed hashes written by the redactor
+var hashPattern = regexp.MustCompile(`^(id|email|ip):[0-9a-f]{12}$`)
+
+func newTestRedactor(t *testing.T, cfg RedactionConfig) *redactor {
+	t.Helper()
+	r, err := newRedactor(cfg)
+	if err != nil {
+		t.Fatalf("newRedactor() error = %v", err)
+	}
+	return r
+}
+
+func TestReplaceAttr(t *testing.T) {
+	r := newTestRedactor(t, RedactionConfig{
+		Keys:       DefaultRedactKeys,
+		HashEmails: true,
+		HashIPs:    true,
+		HashKey:    "test-key",
+	})
+
+	tests := []struct {
+		name   string
+		groups []string
+		attr   slog.Attr
+		want   slog.Value
+	}{
+		{
+			name: "level is kept",
+			attr: slog.String(slog.LevelKey, "INFO"),
+			want: slog.StringValue("INFO"),
+		},
+		{
+			name: "password is redacted",
+			attr: slog.String("password", "hunter2"),
+			want: slog.StringValue(redactedValue),
+		},
+		{
+			name: "keys match case-insensitive substrings",
+			attr: slog.String("csrfToken", "abc"),
+			want: slog.StringValue(redactedValue),
+		},
+		{
+			name: "non-string sensitive values are redacted",
+			attr: slog.Int("tokenVersion", 3),
+			want: slog.StringValue(redactedValue),
+		},
+		{
+			name:   "keys inside groups are redacted",
+			groups: []string{"request"},
+			attr:   slog.String("authorization", "Bearer abc"),
+			want:   slog.StringValue(redactedValue),
+		},
+		{
+			name: "email field is hashed case-insensitively",
+			attr: slog.String("email", "Admin@Blog.com"),
+			want: slog.StringValue(r.hash("id", "admin@blog.com")),
+		},
+		{
+			name: "identifier holding a username is hashed",
+			attr: slog.String("identifier", "alice"),
+			want: slog.StringValue(r.hash("id", "alice")),
+		},
+		{
+			name: "emails inside messages are hashed",
+			attr: slog.String(slog.MessageKey, "reset sent to admin@blog.com"),
+			want: slog.StringValue("reset sent to " + r.hash("email", "admin@blog.com")),
+		},
+		{
+			name: "ip is hashed",
+			attr: slog.String("ip", "192.0.2.1"),
+			want: slog.StringValue(r.hash("ip", "192.0.2.1")),
+		},
+		{
+			name: "ip suffix is hashed",
+			attr: slog.String("clientIp", "2001:db8::1"),
+			want: slog.StringValue(r.hash("ip", "2001:db8::1")),
+		},
+		{
+			name: "ip field without an address is kept",
+			attr: slog.String("ip", "unknown"),
+			want: slog.StringValue("unknown"),
+		},
+		{
+			name: "other values are kept",
+			attr: slog.Int("adminId", 7),
+			want: slog.IntValue(7),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.replaceAttr(tt.groups, tt.attr)
+			if got.Key != tt.attr.Key {
+				t.Errorf("replaceAttr() key = %q, want %q", got.Key, tt.attr.Key)
+			}
+			if !got.Value.Equal(tt.want) {
+				t.Errorf("replaceAttr() value = %v, want %v", got.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplaceAttrNestedValues(t *testing.T) {
+	r := newTestRedactor(t, RedactionConfig{
+		Keys:       DefaultRedactKeys,
+		HashEmails: true,
+		HashKey:    "test-key",
+	})
+
+	type credentials struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Hidden   string `json:"-"`
+		internal string
+	}
+	type request struct {
+		Login   *credentials      `json:"login"`
+		Headers map[string]string `json:"headers"`
+		At      time.Time         `json:"at"`
+	}
+
+	var out bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{ReplaceAttr: r.replaceAttr}))
+	logger.Info("nested",
+		slog.Any("request", request{
+			Login:   &credentials{Username: "alice", Password: "hunter2", Hidden: "hidden", internal: "internal"},
+			Headers: map[string]string{"Authorization": "Bearer abc", "Accept": "application/json"},
+			At:      time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		}),
+		slog.Any("fields", map[string]any{"resetToken": "abc123", "to": []string{"admin@blog.com"}}),
+		slog.Any("cause", errors.New("send to admin@blog.com failed")),
+		slog.Any("ids", []int{1, 2}),
+	)
+
+	line := out.String()
+	for _, secret := range []string{"hunter2", "Bearer abc", "abc123", "admin@blog.com", "hidden", "internal"} {
+		if strings.Contains(line, secret) {
+			t.Errorf("log line leaks %q: %s", secret, line)
+		}
+	}
+
+	var record struct {
+		Request struct {
+			Login   map[string]string `json:"login"`
+			Headers map[string]string `json:"headers"`
+			At      string            `json:"at"`
+		} `json:"request"`
+		Fields struct {
+			ResetToken string            `json:"resetToken"`
+			To         map[string]string `json:"to"`
+		} `json:"fields"`
+		Cause string `json:"cause"`
+		IDs   []int  `json:"ids"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("log line is not JSON: %v: %s", err, line)
+	}
+
+	email := r.hash("email", "admin@blog.com")
+	checks := []struct {
+		name, got, want string
+	}{
+		{"struct field by json tag", record.Request.Login["username"], "alice"},
+		{"struct field", record.Request.Login["password"], redactedValue},
+		{"map value", record.Request.Headers["Authorization"], redactedValue},
+		{"harmless map value", record.Request.Headers["Accept"], "application/json"},
+		{"time is kept", record.Request.At, "2025-01-02T03:04:05Z"},
+		{"map of any", record.Fields.ResetToken, redactedValue},
+		{"slice of strings", record.Fields.To["0"], email},
+		{"error text", record.Cause, "send to " + email + " failed"},
+	}
+	for _, check := range checks {
+		if check.got != check.want {
+			t.Errorf("%s = %q, want %q", check.name, check.got, check.want)
+		}
+	}
+	if len(record.IDs) != 2 {
+		t.Errorf("slice of numbers = %v, want it logged as a list", record.IDs)
+	}
+}
+
+func TestReplaceAttrCycle(t *testing.T) {
+	r := newTestRedactor(t, RedactionConfig{Keys: DefaultRedactKeys, HashKey: "test-key"})
+
+	type node struct {
+		Next *node `json:"next"`
+	}
+	loop := &node{}
+	loop.Next = loop
+
+	var out bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{ReplaceAttr: r.replaceAttr}))
+	logger.Info("cycle", slog.Any("node", loop))
+
+	if depth := strings.Count(out.String(), `"next"`); depth != maxRedactDepth {
+		t.Errorf("cycle expanded %d levels, want %d: %s", depth, maxRedactDepth, out.String())
+	}
+	if !strings.Contains(out.String(), `"next":"`+truncatedValue+`"`) {
+		t.Errorf("cycle was not cut off with %s: %s", truncatedValue, out.String())
+	}
+
+	// A value pointing to itself through an interface never reaches a map, struct or slice
+	var self any
+	self = &self
+	out.Reset()
+	logger.Info("cycle", slog.Any("self", self))
+	if !strings.Contains(out.String(), `"self":"`+truncatedValue+`"`) {
+		t.Errorf("pointer cycle was not cut off with %s: %s", truncatedValue, out.String())
+	}
+}
+
+func TestReplaceAttrHashingDisabled(t *testing.T) {
+	r := newTestRedactor(t, RedactionConfig{Keys: DefaultRedactKeys})
+
+	for _, attr := range []slog.Attr{
+		slog.String("email", "admin@blog.com"),
+		slog.String("ip", "192.0.2.1"),
+		slog.String(slog.MessageKey, "reset sent to admin@blog.com"),
+	} {
+		if got := r.replaceAttr(nil, attr); !got.Value.Equal(attr.Value) {
+			t.Errorf("replaceAttr(%s) = %v, want it unchanged", attr.Key, got.Value)
+		}
+	}
+}
+
+func TestRedactorHash(t *testing.T) {
+	r := newTestRedactor(t, RedactionConfig{HashKey: "test-key"})
+	other := newTestRedactor(t, RedactionConfig{HashKey: "other-key"})
+
+	hash := r.hash("email", "admin@blog.com")
+	if !hashPattern.MatchString(hash) {
+		t.Errorf("hash() = %q, want kind:12 hex digits", hash)
+	}
+	if again := r.hash("email", "admin@blog.com"); again != hash {
+		t.Errorf("hash() is not stable: %q then %q", hash, again)
+	}
+	if keyed := other.hash("email", "admin@blog.com"); keyed == hash {
+		t.Errorf("hash() ignores the key: both keys produced %q", hash)
+	}
+
+	random := newTestRedactor(t, RedactionConfig{})
+	if len(random.hashKey) != 32 {
+		t.Errorf("empty HashKey generated a %d byte key, want 32", len(random.hashKey))
+	}
+}
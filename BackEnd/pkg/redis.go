@@ -1,13 +1,15 @@
 package pkg
 
 import (
-	"os"
-
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
-func RedisConnect() *redis.Client {
-	redisHost := os.Getenv("RDSHOST")
-	redisPort := os.Getenv("RDSPORT")
-	return redis.NewClient(&redis.Options{Addr: redisHost + ":" + redisPort})
+// RedisConnect creates a Redis client that records a span for every command
+func RedisConnect(addr, password string, db int) *redis.Client {
+	rdb := redis.NewClient(&redis.Options{Addr: addr, Password: password, DB: db})
+	if err := redisotel.InstrumentTracing(rdb); err != nil {
+		Warn("Failed to instrument Redis tracing: " + err.Error())
+	}
+	return rdb
 }
@@ -0,0 +1,41 @@
+package pkg
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// SecurityHeadersConfig holds the security headers added to every response
+type SecurityHeadersConfig struct {
+	HSTSMaxAge            int
+	HSTSIncludeSubdomains bool
+	FrameOptions          string
+	ReferrerPolicy        string
+	ContentSecurityPolicy string
+}
+
+// ParseTrustedProxies parses the IP addresses and CIDR ranges accepted by gin's SetTrustedProxies
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid proxy address %q", proxy)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy range %q", proxy)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
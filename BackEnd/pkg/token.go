@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix marks personal API keys so they can be told apart from JWTs
+const APIKeyPrefix = "bk_"
+
+// GenerateOpaqueToken creates a random URL-safe token for links sent to users
+func GenerateOpaqueToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// HashOpaqueToken hashes a token for storage so it can be looked up but not recovered
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateAPIKey creates a personal API key in the form bk_<prefix>_<secret>.
+// The prefix is stored in clear text to look the key up; only the hash of the full key is stored.
+func GenerateAPIKey() (key string, prefix string, err error) {
+	rawPrefix := make([]byte, 6)
+	if _, err := rand.Read(rawPrefix); err != nil {
+		return "", "", err
+	}
+	secret, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	prefix = hex.EncodeToString(rawPrefix)
+	return APIKeyPrefix + prefix + "_" + secret, prefix, nil
+}
+
+// ParseAPIKey extracts the lookup prefix from a personal API key
+func ParseAPIKey(key string) (string, bool) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return "", false
+	}
+	prefix, secret, found := strings.Cut(strings.TrimPrefix(key, APIKeyPrefix), "_")
+	if !found || len(prefix) != 12 || secret == "" {
+		return "", false
+	}
+	return prefix, true
+}
+
+// GenerateRequestID creates a random ID used to correlate the log lines of one request
+func GenerateRequestID() string {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(raw)
+}
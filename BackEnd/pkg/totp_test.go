@@ -0,0 +1,113 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 test key of RFC 6238 appendix B, "12345678901234567890", in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// rfc6238Vectors are the SHA1 test vectors of RFC 6238 appendix B, truncated to six digits
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestValidateTOTPRFC6238(t *testing.T) {
+	for _, vector := range rfc6238Vectors {
+		step, ok := ValidateTOTP(rfc6238Secret, vector.code, time.Unix(vector.unix, 0))
+		if !ok {
+			t.Errorf("code %s at %d was rejected", vector.code, vector.unix)
+			continue
+		}
+		if want := vector.unix / totpPeriod; step != want {
+			t.Errorf("code %s at %d matched step %d, want %d", vector.code, vector.unix, step, want)
+		}
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	// 050471 belongs to step 37037037, from 1111111110 to 1111111139
+	const code, step = "050471", 37037037
+	tests := []struct {
+		name string
+		unix int64
+		ok   bool
+	}{
+		{name: "previous step", unix: 1111111081, ok: true},
+		{name: "next step", unix: 1111111141, ok: true},
+		{name: "two steps late", unix: 1111111170, ok: false},
+		{name: "two steps early", unix: 1111111079, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, ok := ValidateTOTP(rfc6238Secret, code, time.Unix(tt.unix, 0))
+			if ok != tt.ok {
+				t.Fatalf("ValidateTOTP() ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && matched != step {
+				t.Errorf("ValidateTOTP() step = %d, want %d", matched, step)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPInput(t *testing.T) {
+	now := time.Unix(59, 0)
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		ok     bool
+	}{
+		{name: "lowercase secret", secret: strings.ToLower(rfc6238Secret), code: "287082", ok: true},
+		{name: "surrounding spaces", secret: rfc6238Secret, code: " 287082 ", ok: true},
+		{name: "eight digit code", secret: rfc6238Secret, code: "94287082", ok: false},
+		{name: "short code", secret: rfc6238Secret, code: "28708", ok: false},
+		{name: "wrong code", secret: rfc6238Secret, code: "287083", ok: false},
+		{name: "invalid secret", secret: "not base32!", code: "287082", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := ValidateTOTP(tt.secret, tt.code, now); ok != tt.ok {
+				t.Errorf("ValidateTOTP() ok = %v, want %v", ok, tt.ok)
+			}
+		})
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes() error = %v", err)
+	}
+	if len(codes) != 10 {
+		t.Fatalf("GenerateRecoveryCodes(10) returned %d codes", len(codes))
+	}
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		first, second, ok := strings.Cut(code, "-")
+		if !ok || len(first) != 5 || len(second) != 5 {
+			t.Errorf("code %q is not in the form xxxxx-xxxxx", code)
+		}
+		if strings.Trim(first+second, "abcdefghjkmnpqrstuvwxyz23456789") != "" {
+			t.Errorf("code %q uses characters outside the alphabet", code)
+		}
+		if seen[code] {
+			t.Errorf("code %q was generated twice", code)
+		}
+		seen[code] = true
+	}
+}
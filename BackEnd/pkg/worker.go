@@ -0,0 +1,101 @@
+package pkg
+
+import (
+	"context"
+	"sync"
+)
+
+// Worker runs background tasks, such as sending emails after the response, on a fixed number
+// of goroutines with a bounded queue, so a burst of requests cannot spawn unbounded work
+type Worker struct {
+	tasks   chan workerTask
+	wg      sync.WaitGroup
+	mu      sync.RWMutex
+	stopped bool
+	// ctx is cancelled when Stop gives up waiting, telling running tasks to return
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+type workerTask struct {
+	ctx context.Context
+	fn  func(ctx context.Context)
+}
+
+// NewWorker starts workers goroutines that share a queue of queueSize tasks
+func NewWorker(workers, queueSize int) *Worker {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Worker{
+		tasks:  make(chan workerTask, queueSize),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	w.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go w.run()
+	}
+	return w
+}
+
+func (w *Worker) run() {
+	defer w.wg.Done()
+	for task := range w.tasks {
+		if w.ctx.Err() != nil {
+			// Stop gave up waiting, so drop what is still queued
+			continue
+		}
+		// Tasks outlive the request that queued them but keep its values, such as the request logger
+		ctx, cancel := context.WithCancel(context.WithoutCancel(task.ctx))
+		stop := context.AfterFunc(w.ctx, cancel)
+		task.fn(ctx)
+		stop()
+		cancel()
+	}
+}
+
+// Submit queues fn to run with a context carrying the values of ctx. It returns false without
+// running fn when the queue is full or the worker has been stopped.
+func (w *Worker) Submit(ctx context.Context, fn func(ctx context.Context)) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.stopped {
+		return false
+	}
+
+	select {
+	case w.tasks <- workerTask{ctx: ctx, fn: fn}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Stop stops accepting tasks and waits until the queued and running ones have finished.
+// When ctx ends first, the contexts of running tasks are cancelled and queued tasks are
+// dropped, and Stop returns the context error once the running tasks have returned.
+func (w *Worker) Stop(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.stopped {
+		w.stopped = true
+		close(w.tasks)
+	}
+	w.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		w.cancel()
+		return nil
+	case <-ctx.Done():
+	}
+
+	w.cancel()
+	<-done
+	return ctx.Err()
+}
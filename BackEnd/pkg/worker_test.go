@@ -0,0 +1,86 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerStopDrainsQueue(t *testing.T) {
+	w := NewWorker(2, 10)
+
+	var ran atomic.Int32
+	for i := 0; i < 10; i++ {
+		if !w.Submit(context.Background(), func(context.Context) {
+			time.Sleep(time.Millisecond)
+			ran.Add(1)
+		}) {
+			t.Fatalf("Submit() rejected task %d with room in the queue", i)
+		}
+	}
+
+	if err := w.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if ran.Load() != 10 {
+		t.Errorf("Stop() returned after %d of 10 tasks", ran.Load())
+	}
+	if w.Submit(context.Background(), func(context.Context) {}) {
+		t.Errorf("Submit() accepted a task after Stop()")
+	}
+}
+
+func TestWorkerQueueIsBounded(t *testing.T) {
+	w := NewWorker(1, 1)
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	w.Submit(context.Background(), func(context.Context) {
+		close(started)
+		<-release
+	})
+	<-started
+	if !w.Submit(context.Background(), func(context.Context) {}) {
+		t.Fatalf("Submit() rejected a task with room in the queue")
+	}
+	if w.Submit(context.Background(), func(context.Context) {}) {
+		t.Errorf("Submit() accepted a task over the queue size")
+	}
+
+	close(release)
+	if err := w.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+}
+
+func TestWorkerStopTimeoutCancelsTasks(t *testing.T) {
+	w := NewWorker(1, 1)
+	type key struct{}
+	started := make(chan struct{})
+	var cancelled, sawValue atomic.Bool
+
+	ctx, cancelRequest := context.WithCancel(context.WithValue(context.Background(), key{}, "request"))
+	w.Submit(ctx, func(ctx context.Context) {
+		sawValue.Store(ctx.Value(key{}) == "request")
+		close(started)
+		<-ctx.Done()
+		cancelled.Store(true)
+	})
+	<-started
+	// Ending the request does not cancel its background task
+	cancelRequest()
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := w.Stop(stopCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Stop() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if !cancelled.Load() {
+		t.Errorf("Stop() returned before the running task was cancelled")
+	}
+	if !sawValue.Load() {
+		t.Errorf("task context lost the values of the request context")
+	}
+}
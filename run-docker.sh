@@ -53,9 +53,9 @@ docker run -d \
   -e DBNAME=blogku \
   -e JWT_ISSUER=BLOGKU_JWT_ISSUER \
   -e JWT_SECRET=1234_BLOG_KU_PALING_POPULER_1234 \
+  -e AUDIT_HASH_KEY=1234_BLOG_KU_AUDIT_1234 \
   -e RDSHOST=blogku-redis \
   -e RDSPORT=6379 \
-  -e ADMIN_API_KEY=super-secret-admin-api-key-change-me \
   -p 8080:8080 \
   --restart unless-stopped \
   blogku-backend-image